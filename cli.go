@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/library"
+	"github.com/Gylmynnn/dicesong/playlist"
+	"github.com/Gylmynnn/dicesong/query"
+	"github.com/Gylmynnn/dicesong/ratings"
+	"github.com/Gylmynnn/dicesong/stats"
+)
+
+type rebaseFlag map[string]string
+
+func (r rebaseFlag) String() string {
+	var parts []string
+	for from, to := range r {
+		parts = append(parts, from+"="+to)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (r rebaseFlag) Set(value string) error {
+	from, to, ok := strings.Cut(value, "=")
+	if !ok || from == "" {
+		return fmt.Errorf("expected OLD=NEW, got %q", value)
+	}
+	r[from] = to
+	return nil
+}
+
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "playlist":
+		os.Exit(runPlaylist(args[1:]))
+	case "find":
+		os.Exit(runFind(args[1:]))
+	}
+	return false
+}
+
+func runPlaylist(args []string) int {
+	if len(args) == 0 || args[0] != "convert" {
+		fmt.Fprintln(os.Stderr, "usage: dicesong playlist convert [-rebase OLD=NEW] <in> <out>")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("playlist convert", flag.ContinueOnError)
+	rebase := rebaseFlag{}
+	fs.Var(rebase, "rebase", "Replace path prefix OLD with NEW (repeatable)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: dicesong playlist convert [-rebase OLD=NEW] <in> <out>")
+		return 2
+	}
+
+	in, out := fs.Arg(0), fs.Arg(1)
+	n, err := playlist.Convert(in, out, rebase)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to convert playlist:", err)
+		return 1
+	}
+	fmt.Printf("Converted %d tracks: %s -> %s\n", n, in, out)
+	return 0
+}
+
+func runFind(args []string) int {
+	fs := flag.NewFlagSet("find", flag.ContinueOnError)
+	root := fs.String("root", "", "Music directory to search (default ~/Music)")
+	rel := fs.Bool("rel", false, "Print paths relative to the music directory")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: dicesong find [-root DIR] [-rel] <query>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	q, err := query.Parse(strings.Join(fs.Args(), " "))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid query:", err)
+		return 2
+	}
+
+	if *root == "" {
+		if *root, err = library.DefaultRoot(); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to read home directory:", err)
+			return 1
+		}
+	}
+	songs, err := library.Scan(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to scan music directory:", err)
+		return 1
+	}
+
+	ix := library.Open(*root, songs, ratings.Open(), stats.Open())
+	<-ix.Ready()
+
+	found := 0
+	now := time.Now()
+	for _, t := range ix.Tracks() {
+		if !q.Match(t, now) {
+			continue
+		}
+		found++
+		if *rel {
+			fmt.Println(t.Rel)
+		} else {
+			fmt.Println(t.Path)
+		}
+	}
+	if found == 0 {
+		return 1
+	}
+	return 0
+}
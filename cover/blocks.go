@@ -0,0 +1,39 @@
+package cover
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// BlockImage draws img as cols by rows half-block characters, two pixels per
+// cell, in the colors profile can show.
+func BlockImage(img image.Image, cols, rows int, profile termenv.Profile) []string {
+	pixels := fit(img, cols, rows*2)
+	hex := func(c color.NRGBA) termenv.Color {
+		return profile.Color(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+
+	lines := make([]string, rows)
+	for y := range rows {
+		var line strings.Builder
+		for x := range cols {
+			top, bottom := pixels.NRGBAAt(x, 2*y), pixels.NRGBAAt(x, 2*y+1)
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				line.WriteString(" ")
+			case top.A < 128:
+				line.WriteString(termenv.String("▄").Foreground(hex(bottom)).String())
+			case bottom.A < 128:
+				line.WriteString(termenv.String("▀").Foreground(hex(top)).String())
+			default:
+				line.WriteString(termenv.String("▀").Foreground(hex(top)).Background(hex(bottom)).String())
+			}
+		}
+		lines[y] = line.String()
+	}
+	return lines
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cover
+
+// CellSize returns a typical terminal cell size in pixels, as there is no
+// portable way to ask here.
+func CellSize() (int, int) {
+	return defaultCellWidth, defaultCellHeight
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package cover
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// CellSize returns the size of a terminal cell in pixels, as reported by
+// the terminal, or a typical size when it does not say.
+func CellSize() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 || ws.Xpixel == 0 || ws.Ypixel == 0 {
+		return defaultCellWidth, defaultCellHeight
+	}
+	return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+}
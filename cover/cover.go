@@ -0,0 +1,151 @@
+package cover
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/tags"
+)
+
+// Protocol is how covers are drawn in the terminal.
+type Protocol string
+
+const (
+	Auto   Protocol = "auto"
+	Kitty  Protocol = "kitty"
+	Sixel  Protocol = "sixel"
+	Blocks Protocol = "blocks"
+	Off    Protocol = "off"
+)
+
+const (
+	defaultCellWidth  = 10
+	defaultCellHeight = 20
+)
+
+var ErrNotFound = errors.New("no cover found")
+
+// folderNames are the image files looked for next to a song, best first.
+var folderNames = []string{"cover", "folder", "front", "album", "albumart"}
+
+var imageExts = []string{".jpg", ".jpeg", ".png"}
+
+func ParseProtocol(s string) (Protocol, error) {
+	p := Protocol(strings.ToLower(s))
+	switch p {
+	case Auto:
+		return Detect(), nil
+	case Kitty, Sixel, Blocks, Off:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown cover protocol %q", s)
+}
+
+// Detect picks the best protocol the terminal is known to support. Inside
+// tmux or screen images need passthrough, so those get half blocks.
+func Detect() Protocol {
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("TMUX") != "", strings.HasPrefix(term, "screen"), strings.HasPrefix(term, "tmux"):
+		return Blocks
+	case os.Getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty",
+		program == "ghostty", program == "WezTerm":
+		return Kitty
+	case strings.Contains(term, "sixel"), strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "mlterm"),
+		program == "iTerm.app", os.Getenv("KONSOLE_VERSION") != "":
+		return Sixel
+	}
+	return Blocks
+}
+
+// Find returns the cover of the song at path: the picture embedded in its
+// tags, or an image such as cover.jpg in its folder.
+func Find(path string) (image.Image, error) {
+	if data, err := tags.ReadPicture(path); err == nil {
+		if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+			return img, nil
+		}
+	}
+	return folderImage(filepath.Dir(path))
+}
+
+func folderImage(dir string) (image.Image, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	best, rank := "", len(folderNames)
+	for _, file := range files {
+		name := strings.ToLower(file.Name())
+		ext := filepath.Ext(name)
+		if file.IsDir() || !slices.Contains(imageExts, ext) {
+			continue
+		}
+		if i := slices.Index(folderNames, strings.TrimSuffix(name, ext)); i >= 0 && i < rank {
+			best, rank = file.Name(), i
+		}
+	}
+	if best == "" {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(filepath.Join(dir, best))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// fit scales img to fit inside width by height pixels, keeping its aspect
+// ratio, and centers it on a transparent canvas of exactly that size.
+// Each target pixel averages the source pixels it covers.
+func fit(img image.Image, width, height int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	src := img.Bounds()
+	if src.Empty() || width <= 0 || height <= 0 {
+		return dst
+	}
+
+	scale := min(float64(width)/float64(src.Dx()), float64(height)/float64(src.Dy()))
+	w := max(int(float64(src.Dx())*scale), 1)
+	h := max(int(float64(src.Dy())*scale), 1)
+	left, top := (width-w)/2, (height-h)/2
+
+	for y := range h {
+		y0 := src.Min.Y + y*src.Dy()/h
+		y1 := max(src.Min.Y+(y+1)*src.Dy()/h, y0+1)
+		for x := range w {
+			x0 := src.Min.X + x*src.Dx()/w
+			x1 := max(src.Min.X+(x+1)*src.Dx()/w, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(left+x, top+y, color.NRGBA{
+				R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,44 @@
+package cover
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+const (
+	kittyImageID = 7363
+	kittyChunk   = 4096
+)
+
+// KittyDelete removes the cover from the screen and frees it.
+var KittyDelete = fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyImageID)
+
+// KittyImage draws img over cols by rows cells at the cursor without moving the
+// cursor. Drawing it again replaces the earlier placement.
+func KittyImage(img image.Image, cols, rows, cellWidth, cellHeight int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, fit(img, cols*cellWidth, rows*cellHeight)); err != nil {
+		return ""
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var out strings.Builder
+	for i := 0; i < len(data); i += kittyChunk {
+		chunk := data[i:min(i+kittyChunk, len(data))]
+		more := 0
+		if i+kittyChunk < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&out, "\x1b_Ga=T,f=100,i=%d,p=1,c=%d,r=%d,C=1,q=2,m=%d;%s\x1b\\",
+				kittyImageID, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&out, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,112 @@
+package cover
+
+import (
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+)
+
+// cubeLevels is the number of levels per channel in the sixel palette, a
+// 6×6×6 color cube that every sixel terminal can hold.
+const cubeLevels = 6
+
+// SixelImage draws img over cols by rows cells at the cursor. Transparent
+// pixels are left untouched.
+func SixelImage(img image.Image, cols, rows, cellWidth, cellHeight int) string {
+	width, height := cols*cellWidth, rows*cellHeight
+	pixels := fit(img, width, height)
+
+	// Palette index of every pixel, or -1 where it is transparent.
+	index := make([]int, width*height)
+	used := map[int]bool{}
+	for y := range height {
+		for x := range width {
+			c := pixels.NRGBAAt(x, y)
+			i := -1
+			if c.A >= 128 {
+				i = level(c.R)*cubeLevels*cubeLevels + level(c.G)*cubeLevels + level(c.B)
+				used[i] = true
+			}
+			index[y*width+x] = i
+		}
+	}
+
+	var out strings.Builder
+	// P2=1 keeps the background where pixels are not set.
+	fmt.Fprintf(&out, "\x1bP0;1q\"1;1;%d;%d", width, height)
+	colors := make([]int, 0, len(used))
+	for i := range used {
+		colors = append(colors, i)
+	}
+	slices.Sort(colors)
+	for _, i := range colors {
+		r, g, b := i/(cubeLevels*cubeLevels), i/cubeLevels%cubeLevels, i%cubeLevels
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, percent(r), percent(g), percent(b))
+	}
+
+	row := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		bands := map[int][]byte{}
+		for x := range width {
+			for dy := range min(6, height-top) {
+				i := index[(top+dy)*width+x]
+				if i < 0 {
+					continue
+				}
+				band, ok := bands[i]
+				if !ok {
+					band = make([]byte, width)
+					bands[i] = band
+				}
+				band[x] |= 1 << dy
+			}
+		}
+
+		first := true
+		for _, i := range colors {
+			band, ok := bands[i]
+			if !ok {
+				continue
+			}
+			if !first {
+				out.WriteByte('$')
+			}
+			first = false
+			for x := range width {
+				row[x] = '?' + band[x]
+			}
+			fmt.Fprintf(&out, "#%d", i)
+			writeRuns(&out, row)
+		}
+		out.WriteByte('-')
+	}
+	out.WriteString("\x1b\\")
+	return out.String()
+}
+
+// writeRuns writes sixel data with runs of the same sixel compressed.
+func writeRuns(out *strings.Builder, row []byte) {
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, row[x])
+		} else {
+			for range n {
+				out.WriteByte(row[x])
+			}
+		}
+		x += n
+	}
+}
+
+func level(v uint8) int {
+	return (int(v)*(cubeLevels-1) + 127) / 255
+}
+
+func percent(level int) int {
+	return level * 100 / (cubeLevels - 1)
+}
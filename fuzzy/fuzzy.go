@@ -0,0 +1,125 @@
+package fuzzy
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	scoreGapStart    = -3
+	scoreGapExtend   = -1
+	bonusBoundary    = 8
+	bonusConsecutive = 4
+	bonusFirstChar   = 2
+)
+
+type Result struct {
+	Score     int
+	Positions []int
+}
+
+// Match finds pattern as a case-insensitive subsequence of text. Like fzf's
+// v1 algorithm it scans forward for the first complete match and then
+// backward to tighten it, which is fast and good enough for file names.
+func Match(pattern, text string) (Result, bool) {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return Result{}, true
+	}
+	t := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(t) {
+		lower = make([]rune, len(t))
+		for i, r := range t {
+			lower[i] = unicode.ToLower(r)
+		}
+	}
+
+	pi, end := 0, -1
+	for i, r := range lower {
+		if r == p[pi] {
+			pi++
+			if pi == len(p) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return Result{}, false
+	}
+
+	pi = len(p) - 1
+	start := end
+	for i := end; i >= 0; i-- {
+		if lower[i] == p[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	positions := make([]int, 0, len(p))
+	pi = 0
+	for i := start; i <= end && pi < len(p); i++ {
+		if lower[i] == p[pi] {
+			positions = append(positions, i)
+			pi++
+		}
+	}
+	return Result{Score: score(t, positions), Positions: positions}, true
+}
+
+// MatchTerms requires every whitespace separated term of query to match,
+// in any order.
+func MatchTerms(query, text string) (Result, bool) {
+	var result Result
+	for _, term := range strings.Fields(query) {
+		r, ok := Match(term, text)
+		if !ok {
+			return Result{}, false
+		}
+		result.Score += r.Score
+		result.Positions = append(result.Positions, r.Positions...)
+	}
+	slices.Sort(result.Positions)
+	result.Positions = slices.Compact(result.Positions)
+	return result, true
+}
+
+func score(text []rune, positions []int) int {
+	total := 0
+	for i, pos := range positions {
+		total += scoreMatch
+		if pos == 0 {
+			total += bonusFirstChar
+		}
+		if isBoundary(text, pos) {
+			total += bonusBoundary
+		}
+		if i == 0 {
+			continue
+		}
+		if gap := pos - positions[i-1] - 1; gap == 0 {
+			total += bonusConsecutive
+		} else {
+			total += scoreGapStart + scoreGapExtend*(gap-1)
+		}
+	}
+	return total
+}
+
+func isBoundary(text []rune, pos int) bool {
+	if pos == 0 {
+		return true
+	}
+	prev, cur := text[pos-1], text[pos]
+	if unicode.IsLower(prev) && unicode.IsUpper(cur) {
+		return true
+	}
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur))
+}
@@ -0,0 +1,82 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		score     int
+		positions []int
+	}{
+		{"", "anything", true, 0, nil},
+		{"abc", "abc", true, 66, []int{0, 1, 2}},
+		{"ABC", "xabc", true, 56, []int{1, 2, 3}},
+		{"fb", "foo bar", true, 45, []int{0, 4}},
+		{"fb", "fooBar", true, 46, []int{0, 3}},
+		// The backward pass drops the earlier, looser start.
+		{"ab", "a_a_b", true, 45, []int{2, 4}},
+		{"é", "CAFÉ", true, 16, []int{3}},
+		{"i", "İx", true, 26, []int{0}},
+		{"abd", "abc", false, 0, nil},
+		{"ba", "ab", false, 0, nil},
+		{"a", "", false, 0, nil},
+	}
+	for _, tt := range tests {
+		got, ok := Match(tt.pattern, tt.text)
+		if ok != tt.ok {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.ok)
+			continue
+		}
+		if got.Score != tt.score || !reflect.DeepEqual(got.Positions, tt.positions) {
+			t.Errorf("Match(%q, %q) = %d %v, want %d %v", tt.pattern, tt.text, got.Score, got.Positions, tt.score, tt.positions)
+		}
+	}
+}
+
+func TestMatchRanking(t *testing.T) {
+	// Each pair lists the text that should rank higher first.
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		{"song", "song.mp3", "my song.mp3"},
+		{"song", "my song.mp3", "mysong.mp3"},
+		{"ab", "AlbumBest", "alabaster"},
+	}
+	for _, tt := range tests {
+		better, _ := Match(tt.pattern, tt.better)
+		worse, _ := Match(tt.pattern, tt.worse)
+		if better.Score <= worse.Score {
+			t.Errorf("Match(%q): %q scored %d, not above %q at %d", tt.pattern, tt.better, better.Score, tt.worse, worse.Score)
+		}
+	}
+}
+
+func TestMatchTerms(t *testing.T) {
+	tests := []struct {
+		query     string
+		text      string
+		ok        bool
+		score     int
+		positions []int
+	}{
+		{"", "abc", true, 0, nil},
+		{"bar foo", "foo bar", true, 130, []int{0, 1, 2, 4, 5, 6}},
+		{"fo oo", "foo", true, 82, []int{0, 1, 2}},
+		{"x foo", "foo", false, 0, nil},
+	}
+	for _, tt := range tests {
+		got, ok := MatchTerms(tt.query, tt.text)
+		if ok != tt.ok {
+			t.Errorf("MatchTerms(%q, %q) ok = %v, want %v", tt.query, tt.text, ok, tt.ok)
+			continue
+		}
+		if got.Score != tt.score || !reflect.DeepEqual(got.Positions, tt.positions) {
+			t.Errorf("MatchTerms(%q, %q) = %d %v, want %d %v", tt.query, tt.text, got.Score, got.Positions, tt.score, tt.positions)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package keymap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+const configFile = "keys.json"
+
+type Action string
+
+const (
+	Up       Action = "up"
+	Down     Action = "down"
+	Open     Action = "open"
+	Back     Action = "back"
+	Select   Action = "select"
+	Cancel   Action = "cancel"
+	View     Action = "view"
+	Sort     Action = "sort"
+	Reverse  Action = "reverse_sort"
+	Columns  Action = "columns"
+	Pause    Action = "pause"
+	Next     Action = "next"
+	Previous Action = "previous"
+	Repeat   Action = "repeat"
+	Shuffle  Action = "shuffle"
+
+	SaveQueue     Action = "save_queue"
+	AddToPlaylist Action = "add_to_playlist"
+	Playlists     Action = "playlists"
+
+	PlaylistCreate   Action = "playlist_create"
+	PlaylistRename   Action = "playlist_rename"
+	PlaylistDelete   Action = "playlist_delete"
+	PlaylistRemove   Action = "playlist_remove_track"
+	PlaylistMoveUp   Action = "playlist_move_up"
+	PlaylistMoveDown Action = "playlist_move_down"
+	PlaylistExport   Action = "playlist_export"
+	SmartCreate      Action = "smart_create"
+	SmartEdit        Action = "smart_edit"
+	SmartRefresh     Action = "smart_refresh"
+	Favorite         Action = "favorite"
+	RateUp           Action = "rate_up"
+	RateDown         Action = "rate_down"
+	FavoritePlaying  Action = "favorite_playing"
+	Search           Action = "search"
+	SearchScope      Action = "search_scope"
+	SearchSave       Action = "search_save"
+	SearchEnqueue    Action = "search_enqueue"
+	Command          Action = "command"
+	CommandComplete  Action = "command_complete"
+	QueuePane        Action = "queue_pane"
+	InfoPane         Action = "info_pane"
+	LyricsPane       Action = "lyrics_pane"
+	VisualizerPane   Action = "visualizer_pane"
+	Waveform         Action = "waveform"
+	LyricsEarlier    Action = "lyrics_earlier"
+	LyricsLater      Action = "lyrics_later"
+	FocusNext        Action = "focus_next"
+	FocusPrevious    Action = "focus_previous"
+	PaneWider        Action = "pane_wider"
+	PaneNarrower     Action = "pane_narrower"
+	Help             Action = "help"
+	Theme            Action = "theme"
+	Quit             Action = "quit"
+)
+
+// RateActions rate the selected song with their index as the star count.
+var RateActions = []Action{"rate_0", "rate_1", "rate_2", "rate_3", "rate_4", "rate_5"}
+
+// Scope is where a binding applies. Shared bindings apply in every list;
+// the others only while that part of the UI has focus.
+type Scope int
+
+const (
+	ScopeShared Scope = iota
+	ScopeBrowser
+	ScopePane
+	ScopeSearch
+	ScopeCommand
+)
+
+type binding struct {
+	action Action
+	scope  Scope
+	keys   []string
+	help   string
+}
+
+type section struct {
+	title    string
+	bindings []binding
+}
+
+var sections = []section{
+	{"Navigation", []binding{
+		{Up, ScopeShared, []string{"up", "k"}, "Move cursor up"},
+		{Down, ScopeShared, []string{"down", "j"}, "Move cursor down"},
+		{Open, ScopeShared, []string{"right", "l"}, "Enter folder or playlist"},
+		{Back, ScopeShared, []string{"left", "h", "backspace"}, "Go back to parent folder"},
+		{Select, ScopeShared, []string{"enter"}, "Play selected song / Enter folder or playlist"},
+		{Cancel, ScopeShared, []string{"esc"}, "Close search, pane or picker"},
+		{View, ScopeBrowser, []string{"v"}, "Switch view: Files, Artists, Genres, Years"},
+		{Sort, ScopeBrowser, []string{"o"}, "Cycle sort: name, modified, size, duration, track, added"},
+		{Reverse, ScopeBrowser, []string{"O"}, "Reverse sort direction"},
+		{Columns, ScopeBrowser, []string{"c"}, "Show / hide duration, format and quality columns"},
+	}},
+	{"Playback Controls", []binding{
+		{Pause, ScopeBrowser, []string{"p"}, "Play / Pause"},
+		{Next, ScopeBrowser, []string{"n"}, "Next track"},
+		{Previous, ScopeBrowser, []string{"b"}, "Previous track (back)"},
+	}},
+	{"Playback Modes", []binding{
+		{Repeat, ScopeBrowser, []string{"r"}, "Toggle repeat mode"},
+		{Shuffle, ScopeBrowser, []string{"s"}, "Toggle shuffle mode"},
+	}},
+	{"Playlists", []binding{
+		{SaveQueue, ScopeBrowser, []string{"w"}, "Save current queue as an M3U8 playlist"},
+		{AddToPlaylist, ScopeBrowser, []string{"a"}, "Add selected song/folder to a named playlist"},
+		{Playlists, ScopeBrowser, []string{"P"}, "Open / close the playlists pane"},
+	}},
+	{"Playlists Pane", []binding{
+		{PlaylistCreate, ScopePane, []string{"c"}, "Create playlist"},
+		{PlaylistRename, ScopePane, []string{"e"}, "Rename playlist"},
+		{PlaylistDelete, ScopePane, []string{"D"}, "Delete playlist"},
+		{PlaylistRemove, ScopePane, []string{"d"}, "Remove track from playlist or queue"},
+		{PlaylistMoveUp, ScopePane, []string{"K"}, "Move track up"},
+		{PlaylistMoveDown, ScopePane, []string{"J"}, "Move track down"},
+		{PlaylistExport, ScopePane, []string{"x"}, "Export playlist to M3U8"},
+		{SmartCreate, ScopePane, []string{"C"}, "Create smart playlist from rules"},
+		{SmartEdit, ScopePane, []string{"E"}, "Edit smart playlist rules"},
+		{SmartRefresh, ScopePane, []string{"u"}, "Refresh smart playlist"},
+	}},
+	{"Ratings", []binding{
+		{RateActions[0], ScopeBrowser, []string{"0"}, "Rate selected song (0 clears)"},
+		{RateActions[1], ScopeBrowser, []string{"1"}, "Rate selected song (0 clears)"},
+		{RateActions[2], ScopeBrowser, []string{"2"}, "Rate selected song (0 clears)"},
+		{RateActions[3], ScopeBrowser, []string{"3"}, "Rate selected song (0 clears)"},
+		{RateActions[4], ScopeBrowser, []string{"4"}, "Rate selected song (0 clears)"},
+		{RateActions[5], ScopeBrowser, []string{"5"}, "Rate selected song (0 clears)"},
+		{Favorite, ScopeBrowser, []string{"f"}, "Toggle favorite on selected song"},
+		{RateUp, ScopeBrowser, []string{"+", "="}, "Raise rating of playing song"},
+		{RateDown, ScopeBrowser, []string{"-"}, "Lower rating of playing song"},
+		{FavoritePlaying, ScopeBrowser, []string{"F"}, "Toggle favorite on playing song"},
+	}},
+	{"Search", []binding{
+		{Search, ScopeBrowser, []string{"/"}, "Search songs"},
+		{SearchScope, ScopeSearch, []string{"tab"}, "Switch search between current folder and whole library"},
+		{SearchSave, ScopeSearch, []string{"ctrl+s"}, "Save search results as an M3U8 playlist"},
+		{SearchEnqueue, ScopeSearch, []string{"ctrl+e"}, "Queue selected search result to play next"},
+	}},
+	{"Command Line", []binding{
+		{Command, ScopeBrowser, []string{":"}, "Run a command: seek, vol, add, save, theme, cd, quit (↑/↓ for history)"},
+		{CommandComplete, ScopeCommand, []string{"tab"}, "Complete command, theme, playlist or folder name"},
+	}},
+	{"Layout", []binding{
+		{QueuePane, ScopeBrowser, []string{"Q"}, "Show / hide the queue pane"},
+		{InfoPane, ScopeBrowser, []string{"i"}, "Show / hide the track info pane"},
+		{LyricsPane, ScopeBrowser, []string{"L"}, "Show / hide the lyrics pane"},
+		{VisualizerPane, ScopeBrowser, []string{"V"}, "Show / hide the spectrum visualizer pane"},
+		{Waveform, ScopeBrowser, []string{"W"}, "Switch the progress bar between a line and a waveform"},
+		{FocusNext, ScopeBrowser, []string{"tab"}, "Focus next pane"},
+		{FocusPrevious, ScopeBrowser, []string{"shift+tab"}, "Focus previous pane"},
+		{PaneWider, ScopeBrowser, []string{"<"}, "Widen side panes"},
+		{PaneNarrower, ScopeBrowser, []string{">"}, "Narrow side panes"},
+	}},
+	{"Lyrics", []binding{
+		{LyricsEarlier, ScopeBrowser, []string{"["}, "Show lyrics earlier (0.1s)"},
+		{LyricsLater, ScopeBrowser, []string{"]"}, "Show lyrics later (0.1s)"},
+	}},
+	{"General", []binding{
+		{Help, ScopeBrowser, []string{"?"}, "Show / hide this help"},
+		{Theme, ScopeBrowser, []string{"T"}, "Switch color theme"},
+		{Quit, ScopeBrowser, []string{"q", "ctrl+c"}, "Quit application"},
+	}},
+}
+
+type Keymap struct {
+	keys   map[Action][]string
+	lookup map[Scope]map[string]Action
+}
+
+// Line is one row of help: the keys of an action, or of several
+// consecutive actions sharing the same description.
+type Line struct {
+	Keys []string
+	Help string
+}
+
+type Section struct {
+	Title string
+	Lines []Line
+}
+
+func Default() *Keymap {
+	k, _ := build(nil)
+	return k
+}
+
+// Load reads key overrides from keys.json in the config directory. The
+// file maps action names to key lists; actions it leaves out keep their
+// default keys and an empty list unbinds an action.
+func Load() (*Keymap, error) {
+	path := ConfigPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides map[Action][]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	k, err := build(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+func ConfigPath() string {
+	return filepath.Join(state.ConfigDir(), configFile)
+}
+
+func build(overrides map[Action][]string) (*Keymap, error) {
+	k := &Keymap{
+		keys: map[Action][]string{},
+		lookup: map[Scope]map[string]Action{
+			ScopeBrowser: {},
+			ScopePane:    {},
+			ScopeSearch:  {},
+			ScopeCommand: {},
+		},
+	}
+
+	scopes := map[Action]Scope{}
+	for _, s := range sections {
+		for _, b := range s.bindings {
+			scopes[b.action] = b.scope
+			k.keys[b.action] = b.keys
+		}
+	}
+	for action, keys := range overrides {
+		if _, ok := scopes[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q", action)
+		}
+		normalized := make([]string, len(keys))
+		for i, key := range keys {
+			normalized[i] = normalize(key)
+		}
+		k.keys[action] = normalized
+	}
+
+	for _, s := range sections {
+		for _, b := range s.bindings {
+			for _, key := range k.keys[b.action] {
+				if err := k.bind(b.action, b.scope, key); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return k, nil
+}
+
+func (k *Keymap) bind(action Action, scope Scope, key string) error {
+	targets := []Scope{scope}
+	switch scope {
+	case ScopeShared:
+		targets = []Scope{ScopeBrowser, ScopePane, ScopeSearch, ScopeCommand}
+	case ScopeSearch, ScopeCommand:
+		// Printable keys are typed into the query or command.
+		if printable(key) {
+			return fmt.Errorf("key %q for %s would be typed into the input", key, action)
+		}
+	}
+
+	for _, target := range targets {
+		if (target == ScopeSearch || target == ScopeCommand) && printable(key) {
+			continue
+		}
+		if other, ok := k.lookup[target][key]; ok && other != action {
+			return fmt.Errorf("key %q is bound to both %s and %s", key, other, action)
+		}
+		k.lookup[target][key] = action
+	}
+	return nil
+}
+
+// Action returns the action bound to key in scope, or "" if there is none.
+func (k *Keymap) Action(scope Scope, key string) Action {
+	return k.lookup[scope][key]
+}
+
+func (k *Keymap) Keys(action Action) []string {
+	return k.keys[action]
+}
+
+// Sections lists the bindings for help screens, grouped by category.
+func (k *Keymap) Sections() []Section {
+	var out []Section
+	for _, s := range sections {
+		section := Section{Title: s.title}
+		for _, b := range s.bindings {
+			keys := k.keys[b.action]
+			if len(keys) == 0 {
+				continue
+			}
+			if n := len(section.Lines); n > 0 && section.Lines[n-1].Help == b.help {
+				section.Lines[n-1].Keys = append(slices.Clone(section.Lines[n-1].Keys), keys...)
+				continue
+			}
+			section.Lines = append(section.Lines, Line{Keys: keys, Help: b.help})
+		}
+		if len(section.Lines) > 0 {
+			out = append(out, section)
+		}
+	}
+	return out
+}
+
+func normalize(key string) string {
+	switch strings.ToLower(key) {
+	case "space":
+		return " "
+	case "return":
+		return "enter"
+	case "escape":
+		return "esc"
+	}
+	return key
+}
+
+func printable(key string) bool {
+	r, size := utf8.DecodeRuneInString(key)
+	return size == len(key) && unicode.IsPrint(r)
+}
+
+var displayNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"enter":     "Enter",
+	"esc":       "Esc",
+	"tab":       "Tab",
+	"backspace": "Backspace",
+	" ":         "Space",
+}
+
+// Display formats a key the way help screens show it, e.g. "ctrl+s" as
+// "Ctrl+S".
+func Display(key string) string {
+	if name, ok := displayNames[key]; ok {
+		return name
+	}
+	if utf8.RuneCountInString(key) == 1 {
+		return key
+	}
+	parts := strings.Split(key, "+")
+	if len(parts) == 1 {
+		return key
+	}
+	for i, part := range parts {
+		if name, ok := displayNames[part]; ok {
+			parts[i] = name
+		} else if i < len(parts)-1 || len(part) > 1 {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		} else {
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// KeysWidth is the width of the widest key column in sections, for
+// lining up the descriptions.
+func KeysWidth(sections []Section) int {
+	width := 0
+	for _, section := range sections {
+		for _, line := range section.Lines {
+			width = max(width, utf8.RuneCountInString(DisplayKeys(line.Keys)))
+		}
+	}
+	return width
+}
+
+// DisplayKeys joins the keys of a help line, e.g. "↑ / k".
+func DisplayKeys(keys []string) string {
+	shown := make([]string, len(keys))
+	for i, key := range keys {
+		shown[i] = Display(key)
+	}
+	sep := " / "
+	if len(keys) > 3 {
+		sep = " "
+	}
+	return strings.Join(shown, sep)
+}
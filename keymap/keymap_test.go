@@ -0,0 +1,175 @@
+package keymap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	k := Default()
+	tests := []struct {
+		scope Scope
+		key   string
+		want  Action
+	}{
+		{ScopeBrowser, "k", Up},
+		{ScopePane, "k", Up},
+		{ScopeSearch, "up", Up},
+		// Printable shared keys are typed into the search query instead.
+		{ScopeSearch, "k", ""},
+		{ScopeSearch, "tab", SearchScope},
+		{ScopeBrowser, "c", Columns},
+		{ScopePane, "c", PlaylistCreate},
+		{ScopeBrowser, "ctrl+s", ""},
+		{ScopeBrowser, "3", RateActions[3]},
+		{ScopeBrowser, "=", RateUp},
+		{ScopeBrowser, "ctrl+c", Quit},
+		{ScopePane, "q", ""},
+	}
+	for _, tt := range tests {
+		if got := k.Action(tt.scope, tt.key); got != tt.want {
+			t.Errorf("Action(%d, %q) = %q, want %q", tt.scope, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[Action][]string
+		err       string
+		scope     Scope
+		key       string
+		want      Action
+	}{
+		{
+			name:      "rebind",
+			overrides: map[Action][]string{Search: {"ctrl+f"}},
+			scope:     ScopeBrowser, key: "ctrl+f", want: Search,
+		},
+		{
+			name:      "old key is freed",
+			overrides: map[Action][]string{Search: {"ctrl+f"}},
+			scope:     ScopeBrowser, key: "/", want: "",
+		},
+		{
+			name:      "unbind",
+			overrides: map[Action][]string{Quit: {}},
+			scope:     ScopeBrowser, key: "q", want: "",
+		},
+		{
+			name:      "swap keys",
+			overrides: map[Action][]string{Quit: {"ctrl+c"}, Pause: {"q"}},
+			scope:     ScopeBrowser, key: "q", want: Pause,
+		},
+		{
+			name:      "key names",
+			overrides: map[Action][]string{Pause: {"Space"}, Cancel: {"Escape"}},
+			scope:     ScopeBrowser, key: " ", want: Pause,
+		},
+		{
+			name:      "normalized escape",
+			overrides: map[Action][]string{Cancel: {"Escape"}},
+			scope:     ScopeSearch, key: "esc", want: Cancel,
+		},
+		{
+			name:      "same key in other scopes",
+			overrides: map[Action][]string{PlaylistRename: {"v"}},
+			scope:     ScopePane, key: "v", want: PlaylistRename,
+		},
+		{
+			name:      "unknown action",
+			overrides: map[Action][]string{"dance": {"d"}},
+			err:       `unknown action "dance"`,
+		},
+		{
+			name:      "conflict in browser",
+			overrides: map[Action][]string{Pause: {"q"}},
+			err:       `key "q" is bound to both pause and quit`,
+		},
+		{
+			name:      "conflict in pane",
+			overrides: map[Action][]string{PlaylistCreate: {"x"}},
+			err:       `key "x" is bound to both playlist_create and playlist_export`,
+		},
+		{
+			name:      "shared key conflicts with browser key",
+			overrides: map[Action][]string{Up: {"p"}},
+			err:       `key "p" is bound to both up and pause`,
+		},
+		{
+			name:      "printable search key",
+			overrides: map[Action][]string{SearchSave: {"s"}},
+			err:       `"s" for search_save would be typed into the`,
+		},
+	}
+	for _, tt := range tests {
+		k, err := build(tt.overrides)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := k.Action(tt.scope, tt.key); got != tt.want {
+			t.Errorf("%s: Action(%d, %q) = %q, want %q", tt.name, tt.scope, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSections(t *testing.T) {
+	k, err := build(map[Action][]string{Repeat: {}, Shuffle: {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range k.Sections() {
+		if s.Title == "Playback Modes" {
+			t.Error("section without bound keys is listed")
+		}
+		if s.Title != "Ratings" {
+			continue
+		}
+		want := Line{Keys: []string{"0", "1", "2", "3", "4", "5"}, Help: "Rate selected song (0 clears)"}
+		if !reflect.DeepEqual(s.Lines[0], want) {
+			t.Errorf("rating keys not merged into one line: %+v", s.Lines[0])
+		}
+		// Merging must not write into the default key lists.
+		if keys := k.Keys(RateActions[0]); !reflect.DeepEqual(keys, []string{"0"}) {
+			t.Errorf("Keys(rate_0) = %q after Sections", keys)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"k", "k"},
+		{"K", "K"},
+		{"up", "↑"},
+		{" ", "Space"},
+		{"f1", "f1"},
+		{"ctrl+s", "Ctrl+S"},
+		{"shift+tab", "Shift+Tab"},
+		{"ctrl+left", "Ctrl+←"},
+		{"alt+enter", "Alt+Enter"},
+	}
+	for _, tt := range tests {
+		if got := Display(tt.key); got != tt.want {
+			t.Errorf("Display(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	if got := DisplayKeys([]string{"up", "k"}); got != "↑ / k" {
+		t.Errorf("DisplayKeys = %q", got)
+	}
+	if got := DisplayKeys(strings.Split("012345", "")); got != "0 1 2 3 4 5" {
+		t.Errorf("DisplayKeys = %q", got)
+	}
+}
@@ -0,0 +1,243 @@
+package library
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/ratings"
+	"github.com/Gylmynnn/dicesong/state"
+	"github.com/Gylmynnn/dicesong/stats"
+	"github.com/Gylmynnn/dicesong/tags"
+)
+
+const cacheFile = "library.json"
+
+type Track struct {
+	Path       string
+	Rel        string
+	Ext        string
+	Size       int64
+	ModTime    time.Time
+	Added      time.Time
+	Tags       tags.Tags
+	Rating     int
+	Favorite   bool
+	Plays      int
+	LastPlayed time.Time
+}
+
+type cached struct {
+	Size    int64      `json:"size"`
+	ModTime time.Time  `json:"mod_time"`
+	Added   time.Time  `json:"added"`
+	Tags    tags.Tags  `json:"tags"`
+	Info    *tags.Info `json:"info,omitempty"`
+}
+
+type Index struct {
+	mutex     sync.RWMutex
+	saveMutex sync.Mutex
+	root      string
+	songs     []string
+	entries   map[string]cached
+	ratings   *ratings.DB
+	stats     *stats.DB
+	ready     chan struct{}
+}
+
+func DefaultRoot() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Music"), nil
+}
+
+func Scan(root string) ([]string, error) {
+	var songs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && IsAudioFile(d.Name()) {
+			songs = append(songs, path)
+		}
+		return nil
+	})
+	return songs, err
+}
+
+func IsAudioFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".mp3") ||
+		strings.HasSuffix(lower, ".wav") ||
+		strings.HasSuffix(lower, ".flac") ||
+		strings.HasSuffix(lower, ".ogg") ||
+		strings.HasSuffix(lower, ".oga")
+}
+
+func Open(root string, songs []string, ratingDB *ratings.DB, statsDB *stats.DB) *Index {
+	ix := &Index{
+		root:    root,
+		songs:   songs,
+		entries: map[string]cached{},
+		ratings: ratingDB,
+		stats:   statsDB,
+		ready:   make(chan struct{}),
+	}
+	go ix.build()
+	return ix
+}
+
+func (ix *Index) Ready() <-chan struct{} {
+	return ix.ready
+}
+
+func (ix *Index) Root() string {
+	return ix.root
+}
+
+func (ix *Index) Tracks() []Track {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+
+	tracks := make([]Track, 0, len(ix.songs))
+	for _, path := range ix.songs {
+		tracks = append(tracks, ix.track(path))
+	}
+	return tracks
+}
+
+func (ix *Index) Track(path string) Track {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	return ix.track(path)
+}
+
+func (ix *Index) track(path string) Track {
+	entry := ix.entries[path]
+	rel, err := filepath.Rel(ix.root, path)
+	if err != nil {
+		rel = path
+	}
+	t := Track{
+		Path:    path,
+		Rel:     rel,
+		Ext:     strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."),
+		Size:    entry.Size,
+		ModTime: entry.ModTime,
+		Added:   entry.Added,
+		Tags:    entry.Tags,
+	}
+	if ix.ratings != nil {
+		r := ix.ratings.Get(path)
+		t.Rating, t.Favorite = r.Rating, r.Favorite
+	}
+	if ix.stats != nil {
+		s := ix.stats.Get(path)
+		t.Plays, t.LastPlayed = s.Plays, s.LastPlayed
+	}
+	return t
+}
+
+func (ix *Index) build() {
+	defer close(ix.ready)
+
+	cachePath := filepath.Join(state.DataDir(), cacheFile)
+	previous := map[string]cached{}
+	if data, err := os.ReadFile(cachePath); err == nil {
+		_ = json.Unmarshal(data, &previous)
+	}
+
+	// On the very first scan the modification time is the best guess for
+	// when a file joined the library.
+	firstScan := len(previous) == 0
+	now := time.Now()
+	for _, path := range ix.songs {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		entry, ok := previous[path]
+		if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+			added := entry.Added
+			if added.IsZero() {
+				added = now
+				if firstScan {
+					added = info.ModTime()
+				}
+			}
+			t, _ := tags.Read(path)
+			entry = cached{Size: info.Size(), ModTime: info.ModTime(), Added: added, Tags: t}
+		}
+
+		ix.mutex.Lock()
+		if entry.Info == nil {
+			entry.Info = ix.entries[path].Info
+		}
+		ix.entries[path] = entry
+		ix.mutex.Unlock()
+	}
+	ix.save()
+}
+
+// Info returns the cached stream info of path, if it has been probed.
+func (ix *Index) Info(path string) (tags.Info, bool) {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	if info := ix.entries[path].Info; info != nil {
+		return *info, true
+	}
+	return tags.Info{}, false
+}
+
+// Probe reads the stream info of the paths not probed yet and caches it.
+// Files that cannot be read are cached with empty info so they are not
+// probed again until they change.
+func (ix *Index) Probe(paths []string) {
+	changed := false
+	for _, path := range paths {
+		if _, ok := ix.Info(path); ok {
+			continue
+		}
+		info, _ := tags.ReadInfo(path)
+		ix.mutex.Lock()
+		entry := ix.entries[path]
+		entry.Info = &info
+		ix.entries[path] = entry
+		ix.mutex.Unlock()
+		changed = true
+	}
+
+	// Until the scan finishes the cache is incomplete; build saves it.
+	select {
+	case <-ix.ready:
+		if changed {
+			ix.save()
+		}
+	default:
+	}
+}
+
+func (ix *Index) save() {
+	ix.saveMutex.Lock()
+	defer ix.saveMutex.Unlock()
+
+	ix.mutex.RLock()
+	data, err := json.Marshal(ix.entries)
+	ix.mutex.RUnlock()
+	if err != nil {
+		return
+	}
+	cachePath := filepath.Join(state.DataDir(), cacheFile)
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(cachePath, data, 0o644)
+}
@@ -0,0 +1,174 @@
+package lyrics
+
+import (
+	"cmp"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/tags"
+)
+
+var ErrNotFound = errors.New("no lyrics found")
+
+var (
+	// tagPattern matches one [...] tag at the start of an LRC line.
+	tagPattern = regexp.MustCompile(`^\[([^\]]*)\]`)
+	// wordPattern matches the word timestamps of enhanced LRC.
+	wordPattern = regexp.MustCompile(`<\d+:\d+(?:[.:]\d+)?>`)
+)
+
+type Line struct {
+	Time time.Duration
+	Text string
+}
+
+type Lyrics struct {
+	Lines []Line
+	// Synced lyrics have a time for every line, sorted by time.
+	Synced bool
+	// Offset is added to the playback position, so a positive offset
+	// shows the lines earlier. It comes from the [offset:] tag of LRC.
+	Offset time.Duration
+	// Source is the LRC file the lyrics were read from, or empty when
+	// they are embedded in the song.
+	Source string
+}
+
+// Load finds the lyrics of the song at path: an LRC file with the same
+// name next to it, or the lyrics embedded in its tags.
+func Load(path string) (Lyrics, error) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range []string{".lrc", ".LRC"} {
+		data, err := os.ReadFile(base + ext)
+		if err == nil {
+			l := Parse(string(data))
+			l.Source = base + ext
+			return l, nil
+		}
+	}
+
+	embedded, err := tags.ReadLyrics(path)
+	if err != nil {
+		if errors.Is(err, tags.ErrNoLyrics) {
+			return Lyrics{}, ErrNotFound
+		}
+		return Lyrics{}, err
+	}
+	if len(embedded.Synced) > 0 {
+		l := Lyrics{Synced: true}
+		for _, line := range embedded.Synced {
+			l.Lines = append(l.Lines, Line{Time: line.Time, Text: strings.TrimSpace(line.Text)})
+		}
+		slices.SortStableFunc(l.Lines, byTime)
+		return l, nil
+	}
+	return Parse(embedded.Text), nil
+}
+
+// Parse reads LRC text. A line may carry several timestamps, and lines
+// without any are dropped once one has been seen. Text without
+// timestamps gives unsynced lyrics of all its lines.
+func Parse(text string) Lyrics {
+	var l Lyrics
+	var plain []Line
+	for _, raw := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		rest := strings.TrimSpace(raw)
+		var times []time.Duration
+		tagged := false
+		for {
+			m := tagPattern.FindStringSubmatch(rest)
+			if m == nil {
+				break
+			}
+			if t, ok := parseTimestamp(m[1]); ok {
+				times = append(times, t)
+			} else if value, ok := strings.CutPrefix(m[1], "offset:"); ok {
+				if ms, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+					l.Offset = time.Duration(ms) * time.Millisecond
+				}
+			} else if !isMetadata(m[1]) {
+				break
+			}
+			rest, tagged = rest[len(m[0]):], true
+		}
+		rest = strings.TrimSpace(wordPattern.ReplaceAllString(rest, ""))
+
+		if len(times) > 0 {
+			l.Synced = true
+			for _, t := range times {
+				l.Lines = append(l.Lines, Line{Time: t, Text: rest})
+			}
+		} else if !tagged {
+			plain = append(plain, Line{Text: rest})
+		}
+	}
+
+	if l.Synced {
+		slices.SortStableFunc(l.Lines, byTime)
+		return l
+	}
+	// Keep blank lines between verses but not around the lyrics.
+	start := slices.IndexFunc(plain, func(line Line) bool { return line.Text != "" })
+	if start < 0 {
+		return l
+	}
+	end := len(plain)
+	for plain[end-1].Text == "" {
+		end--
+	}
+	l.Lines = plain[start:end]
+	return l
+}
+
+func byTime(a, b Line) int {
+	return cmp.Compare(a.Time, b.Time)
+}
+
+// parseTimestamp reads mm:ss, mm:ss.xx or mm:ss:xx.
+func parseTimestamp(s string) (time.Duration, bool) {
+	mins, rest, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, false
+	}
+	m, err := strconv.Atoi(mins)
+	if err != nil || m < 0 {
+		return 0, false
+	}
+	rest = strings.Replace(rest, ":", ".", 1)
+	secs, err := strconv.ParseFloat(rest, 64)
+	if err != nil || secs < 0 || secs >= 60 || strings.ContainsAny(rest, "eE+-") {
+		return 0, false
+	}
+	return time.Duration(m)*time.Minute + time.Duration(secs*float64(time.Second)), true
+}
+
+// isMetadata reports whether a tag is an ID tag such as [ar:Artist].
+func isMetadata(tag string) bool {
+	key, _, ok := strings.Cut(tag, ":")
+	if !ok || key == "" {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// Current returns the index of the line sung at the given position, or -1
+// before the first one. Unsynced lyrics have no current line.
+func (l Lyrics) Current(position time.Duration) int {
+	if !l.Synced {
+		return -1
+	}
+	position += l.Offset
+	return sort.Search(len(l.Lines), func(i int) bool { return l.Lines[i].Time > position }) - 1
+}
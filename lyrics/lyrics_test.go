@@ -0,0 +1,144 @@
+package lyrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"00:00", 0, true},
+		{"01:02", ms(62000), true},
+		{"01:02.50", ms(62500), true},
+		{"01:02:50", ms(62500), true},
+		{"1:02.5", ms(62500), true},
+		{"10:00.000", ms(600000), true},
+		{"123:45.25", ms(123*60000 + 45250), true},
+		{"", 0, false},
+		{"12", 0, false},
+		{"ar:Artist", 0, false},
+		{"-1:00", 0, false},
+		{"01:60", 0, false},
+		{"01:-5", 0, false},
+		{"01:1e1", 0, false},
+		{"01:+5", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTimestamp(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseTimestamp(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Lyrics
+	}{
+		{
+			name: "synced",
+			text: "[ar:Artist]\n[ti:Title]\n[00:01.00]First\n[00:02.50] Second \n",
+			want: Lyrics{Synced: true, Lines: []Line{
+				{Time: ms(1000), Text: "First"},
+				{Time: ms(2500), Text: "Second"},
+			}},
+		},
+		{
+			name: "repeated lines are sorted",
+			text: "[00:10.00][00:01.00]Chorus\r\n[00:05.00]Verse\r\n",
+			want: Lyrics{Synced: true, Lines: []Line{
+				{Time: ms(1000), Text: "Chorus"},
+				{Time: ms(5000), Text: "Verse"},
+				{Time: ms(10000), Text: "Chorus"},
+			}},
+		},
+		{
+			name: "offset",
+			text: "[offset: -250]\n[00:01.00]Line\n",
+			want: Lyrics{Synced: true, Offset: ms(-250), Lines: []Line{
+				{Time: ms(1000), Text: "Line"},
+			}},
+		},
+		{
+			name: "enhanced word timestamps",
+			text: "[00:01.00]<00:01.00>One <00:01.50>two\n",
+			want: Lyrics{Synced: true, Lines: []Line{
+				{Time: ms(1000), Text: "One two"},
+			}},
+		},
+		{
+			name: "untimed lines in synced lyrics",
+			text: "Intro\n[00:01.00]Line\nstray\n",
+			want: Lyrics{Synced: true, Lines: []Line{
+				{Time: ms(1000), Text: "Line"},
+			}},
+		},
+		{
+			name: "plain",
+			text: "\n\nFirst verse\n\nSecond verse\n[Chorus]\n\n",
+			want: Lyrics{Lines: []Line{
+				{Text: "First verse"},
+				{Text: ""},
+				{Text: "Second verse"},
+				{Text: "[Chorus]"},
+			}},
+		},
+		{
+			name: "metadata only",
+			text: "[ar:Artist]\n[al:Album]\n",
+			want: Lyrics{},
+		},
+		{
+			name: "empty",
+			text: "",
+			want: Lyrics{},
+		},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Parse = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	l := Parse("[00:01.00]One\n[00:02.00]Two\n[00:03.00]Three\n")
+	tests := []struct {
+		offset   time.Duration
+		position time.Duration
+		want     int
+	}{
+		{0, 0, -1},
+		{0, ms(999), -1},
+		{0, ms(1000), 0},
+		{0, ms(2500), 1},
+		{0, ms(3000), 2},
+		{0, time.Hour, 2},
+		{ms(500), ms(500), 0},
+		{ms(500), ms(1499), 0},
+		{ms(500), ms(1500), 1},
+		{ms(-500), ms(1000), -1},
+		{ms(-500), ms(1500), 0},
+	}
+	for _, tt := range tests {
+		l.Offset = tt.offset
+		if got := l.Current(tt.position); got != tt.want {
+			t.Errorf("Current(%v) with offset %v = %d, want %d", tt.position, tt.offset, got, tt.want)
+		}
+	}
+
+	if got := Parse("Just words\n").Current(time.Minute); got != -1 {
+		t.Errorf("Current of unsynced lyrics = %d, want -1", got)
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/Gylmynnn/dicesong/cover"
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/Gylmynnn/dicesong/theme"
 	"github.com/Gylmynnn/dicesong/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func printHelp() {
+func printHelp(keys *keymap.Keymap) {
 	fmt.Print(`
 ╔════════════════════════════════════════════════════════════════╗
 ║                    ♪ DICESONG - TUI Music Player               ║
@@ -17,61 +22,125 @@ func printHelp() {
 
 USAGE:
   dicesong [OPTIONS]
+  dicesong playlist convert [-rebase OLD=NEW] <in> <out>
+  dicesong find [-root DIR] [-rel] <query>
 
 OPTIONS:
-  -h, --help    Show this help message
+  -h, --help       Show this help message
+  --write-tags     Also store ratings in POPM (MP3) / RATING (FLAC, Ogg) tags
+  --ellipsis MODE  Shorten long names at the end (default) or middle
+  --cover MODE     Draw cover art with auto (default), kitty, sixel,
+                   blocks or off
+  --sleep-fade D   Fade out over D before the sleep timer fires
+                   (default 30s)
+  --ramp SPEC      Fade lengths around pause, resume, skip and stop:
+                   one duration for all, or e.g. pause=200ms,skip=0
 
 KEYBOARD SHORTCUTS:
-
-  Navigation:
-    ↑ / k       Move cursor up
-    ↓ / j       Move cursor down
-    → / l       Enter folder
-    ← / h       Go back to parent folder
-    Enter       Play selected song / Enter folder
-
-  Playback Controls:
-    p           Play / Pause
-    n           Next track
-    b           Previous track (back)
-
-  Playback Modes:
-    r           Toggle repeat mode
-    s           Toggle shuffle mode
-
-   General:
-     /           Search songs (Esc to exit search)
-     q           Quit application
-     Ctrl+C      Force quit
-
+`)
+	printKeys(keys)
+	fmt.Printf(`
 FEATURES:
-  • Browse and play MP3 and WAV files
+  • Browse and play MP3, WAV, FLAC and OGG files
+  • Open and save M3U/M3U8 playlists, browse PLS and XSPF
+  • Browse by artist, album, genre or year
+  • Duration, format, sample rate and bit depth / bitrate columns
   • Shuffle and repeat modes
-  • Progress bar with timestamps
+  • Track ratings and favorites
+  • Smart playlists from tag, file and play-count rules
+  • Progress bar with timestamps, or a clickable waveform
+  • Mouse support: click, double-click, wheel and click-to-seek
   • Persistent state (remembers last settings)
   • Responsive design for different terminal sizes
+  • Resizable side panes with the play queue and track info
+  • Synced lyrics from LRC files and embedded tags
+  • Spectrum and oscilloscope visualizer
+  • Album covers via kitty graphics, sixel or colored half blocks
+  • Color themes, including your own, and NO_COLOR support
+  • Command line (:seek, :vol, :cd, ...) with completion and history
+  • Sleep timer that fades out, then pauses or quits
 
 Music directory: ~/Music
 State file: ./state.json
+Key bindings: %s
 
-`)
+`, keymap.ConfigPath())
+}
+
+func printKeys(keys *keymap.Keymap) {
+	sections := keys.Sections()
+	width := keymap.KeysWidth(sections)
+	for _, section := range sections {
+		fmt.Printf("\n  %s:\n", section.Title)
+		for _, line := range section.Lines {
+			fmt.Printf("    %-*s  %s\n", width, keymap.DisplayKeys(line.Keys), line.Help)
+		}
+	}
 }
 
 func main() {
+	runCommand(os.Args[1:])
+
 	help := flag.Bool("h", false, "Show help message")
 	flag.BoolVar(help, "help", false, "Show help message")
+	writeTags := flag.Bool("write-tags", false, "Write ratings back to file tags")
+	ellipsis := flag.String("ellipsis", "end", "Where to shorten long names: end or middle")
+	coverFlag := flag.String("cover", "auto", "How to draw cover art: auto, kitty, sixel, blocks or off")
+	rampFlag := flag.String("ramp", "", "Fade lengths around pause, resume, skip and stop, e.g. 50ms or pause=200ms,skip=0")
+	sleepFade := flag.Duration("sleep-fade", tui.DefaultSleepFade, "How long the sleep timer fades out for")
 	flag.Parse()
 
+	keys, err := keymap.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid key bindings:", err)
+		os.Exit(1)
+	}
+	themes, err := theme.Load()
+	if err != nil {
+		// Load still returns the bundled themes and every file that loaded.
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintln(os.Stderr, "Skipping invalid theme:", line)
+		}
+	}
 	if *help {
-		printHelp()
+		printHelp(keys)
 		os.Exit(0)
 	}
+	if *ellipsis != "end" && *ellipsis != "middle" {
+		fmt.Fprintf(os.Stderr, "invalid --ellipsis %q: use end or middle\n", *ellipsis)
+		os.Exit(2)
+	}
+	protocol, err := cover.ParseProtocol(*coverFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --cover %q: use auto, kitty, sixel, blocks or off\n", *coverFlag)
+		os.Exit(2)
+	}
+	if *sleepFade < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --sleep-fade %s: must not be negative\n", *sleepFade)
+		os.Exit(2)
+	}
+
+	if *rampFlag != "" {
+		ramps, err := player.ParseRamps(*rampFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --ramp: %v\n", err)
+			os.Exit(2)
+		}
+		player.SetRamps(ramps)
+	}
 
 	m := tui.InitialModel()
+	m.WriteRatingTags = *writeTags
+	m.MiddleEllipsis = *ellipsis == "middle"
+	m.Cover = protocol
+	m.SleepFade = *sleepFade
+	m.Keys = keys
+	m.Themes = themes
 	go tui.PlaybackManager(m.PlayRequest, m.DoneChan, m.LoadedChan)
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Println("Failed to launch:", err)
 		os.Exit(1)
 	}
+	player.StopCurrent()
 }
@@ -71,3 +71,7 @@ func Playback(song string, paused bool) {
 func Error(message string) {
 	run("Playback Error", message, "dialog-error")
 }
+
+func Sleep(message string) {
+	run("Sleep Timer", message, "weather-clear-night")
+}
@@ -17,93 +17,157 @@ import (
 )
 
 var (
-	ctrl     *beep.Ctrl
-	seeker   beep.StreamSeekCloser
-	format   beep.Format
-	initOnce sync.Once
-	mutex    sync.Mutex
+	ctrl    *beep.Ctrl
+	current *ramp
+	seeker  beep.StreamSeekCloser
+	format  beep.Format
+	// paused is set as soon as a pause is asked for, while ctrl only
+	// pauses once the pause ramp has faded out.
+	paused      bool
+	speakerRate beep.SampleRate
+	initOnce    sync.Once
+	mutex       sync.Mutex
 )
 
 func InitSpeaker(sampleRate beep.SampleRate) {
 	initOnce.Do(func() {
+		speakerRate = sampleRate
 		speaker.Init(sampleRate, sampleRate.N(time.Second/10))
 	})
 }
 
-func stopCurrent() {
-	speaker.Clear()
-	if seeker != nil {
-		seeker.Close()
-		seeker = nil
-		ctrl = nil
+// stopCurrent detaches the playing song and fades it out over d in the
+// mixer, which drops and closes it once it is silent. It does not wait
+// for that; the returned channel is closed when it happened.
+func stopCurrent(d time.Duration) <-chan struct{} {
+	stopped := make(chan struct{})
+	if current == nil {
+		close(stopped)
+		return stopped
 	}
+
+	r, s := current, seeker
+	finish := func() {
+		r.stopped = true
+		go func() {
+			s.Close()
+			close(stopped)
+		}()
+	}
+	speaker.Lock()
+	r.stopping = true
+	if paused || r.ended || d <= 0 {
+		finish()
+	} else {
+		r.slide(0, d, finish)
+	}
+	speaker.Unlock()
+
+	seeker = nil
+	ctrl = nil
+	current = nil
+	paused = false
+	return stopped
 }
 
+// StopCurrent stops the playing song and waits for it to fade out, or for
+// a little longer than that if the speaker has stalled.
 func StopCurrent() {
 	mutex.Lock()
-	defer mutex.Unlock()
-	stopCurrent()
+	d := ramps.Stop
+	stopped := stopCurrent(d)
+	mutex.Unlock()
+
+	select {
+	case <-stopped:
+	case <-time.After(d + 100*time.Millisecond):
+	}
 }
 
-func PlayMusic(path string, done chan bool) error {
+// Decode opens the song at path for decoding. Closing the stream closes
+// the file.
+func Decode(path string) (beep.StreamSeekCloser, beep.Format, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return err
+		return nil, beep.Format{}, err
 	}
 
 	var stream beep.StreamSeekCloser
-	var localFormat beep.Format
+	var format beep.Format
 
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
 	case ".mp3":
-		stream, localFormat, err = mp3.Decode(f)
+		stream, format, err = mp3.Decode(f)
 	case ".wav":
-		stream, localFormat, err = wav.Decode(f)
+		stream, format, err = wav.Decode(f)
 	case ".flac":
-		stream, localFormat, err = flac.Decode(f)
+		stream, format, err = flac.Decode(f)
 	case ".ogg", ".oga":
-		stream, localFormat, err = vorbis.Decode(f)
+		stream, format, err = vorbis.Decode(f)
 	default:
 		f.Close()
-		return errors.New("format tidak didukung (gunakan: mp3, wav, flac, ogg)")
+		return nil, beep.Format{}, errors.New("format tidak didukung (gunakan: mp3, wav, flac, ogg)")
 	}
 
 	if err != nil {
 		f.Close()
+		return nil, beep.Format{}, err
+	}
+	return stream, format, nil
+}
+
+func PlayMusic(path string, done chan bool) error {
+	stream, localFormat, err := Decode(path)
+	if err != nil {
 		return err
 	}
 
 	mutex.Lock()
 	defer mutex.Unlock()
-	stopCurrent()
+	// The old song fades out in the mixer while the new one fades in.
+	stopCurrent(ramps.Skip)
 	seeker = stream
 	format = localFormat
 	InitSpeaker(format.SampleRate)
-	ctrl = &beep.Ctrl{Streamer: beep.Seq(stream, beep.Callback(func() {
-		done <- true
+	r := &ramp{}
+	ctrl = &beep.Ctrl{Streamer: beep.Seq(tap{stream}, beep.Callback(func() {
+		// A song stopped while fading out ends after the next one started.
+		if !r.stopping {
+			done <- true
+		}
 	})), Paused: false}
-	speaker.Play(ctrl)
+	r.Streamer = ctrl
+	current = r
+	current.slide(1, ramps.Skip, nil)
+	speaker.Play(volumeStreamer{current})
 	return nil
 }
 
+// TogglePause fades out before pausing and fades back in on resuming.
 func TogglePause() {
 	mutex.Lock()
 	defer mutex.Unlock()
-	if ctrl != nil {
-		speaker.Lock()
-		ctrl.Paused = !ctrl.Paused
-		speaker.Unlock()
+	if ctrl == nil {
+		return
 	}
+
+	speaker.Lock()
+	defer speaker.Unlock()
+	paused = !paused
+	if paused {
+		c := ctrl
+		current.slide(0, ramps.Pause, func() { c.Paused = true })
+		return
+	}
+	ctrl.Paused = false
+	current.slide(1, ramps.Resume, nil)
 }
 
 func IsPaused() bool {
 	mutex.Lock()
 	defer mutex.Unlock()
-	if ctrl == nil {
-		return false
-	}
-	return ctrl.Paused
+	return ctrl != nil && paused
 }
 
 func GetProgress() (float64, float64) {
@@ -121,3 +185,25 @@ func GetProgress() (float64, float64) {
 
 	return float64(position), float64(length)
 }
+
+// Seek jumps to the given fraction of the current song.
+func Seek(fraction float64) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if seeker == nil || ctrl == nil {
+		return nil
+	}
+
+	length := seeker.Len()
+	position := int(float64(length) * min(max(fraction, 0), 1))
+	speaker.Lock()
+	defer speaker.Unlock()
+	return seeker.Seek(min(position, max(length-1, 0)))
+}
+
+// CurrentFormat returns the format the playing song is decoded with.
+func CurrentFormat() (beep.Format, bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return format, seeker != nil
+}
@@ -0,0 +1,147 @@
+package player
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Ramps are how long the gain takes to slide out or in around playback
+// changes, so that cutting a waveform mid-swing does not click.
+type Ramps struct {
+	Pause  time.Duration
+	Resume time.Duration
+	// Skip fades the old song out and the new one in when changing songs.
+	Skip time.Duration
+	Stop time.Duration
+}
+
+var DefaultRamps = Ramps{
+	Pause:  100 * time.Millisecond,
+	Resume: 100 * time.Millisecond,
+	Skip:   30 * time.Millisecond,
+	Stop:   50 * time.Millisecond,
+}
+
+var ramps = DefaultRamps
+
+// SetRamps sets the ramps used from the next playback change on.
+func SetRamps(r Ramps) {
+	mutex.Lock()
+	ramps = r
+	mutex.Unlock()
+}
+
+// ParseRamps reads a duration for every ramp, such as "50ms", or a list
+// of ramps to change from the defaults, such as "pause=200ms,skip=0".
+func ParseRamps(spec string) (Ramps, error) {
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d < 0 {
+			return Ramps{}, fmt.Errorf("negative ramp %s", spec)
+		}
+		return Ramps{Pause: d, Resume: d, Skip: d, Stop: d}, nil
+	}
+
+	r := DefaultRamps
+	fields := map[string]*time.Duration{
+		"pause":  &r.Pause,
+		"resume": &r.Resume,
+		"skip":   &r.Skip,
+		"stop":   &r.Stop,
+	}
+	for _, part := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		field, known := fields[name]
+		if !ok || !known {
+			return Ramps{}, fmt.Errorf("invalid ramp %q: use NAME=DURATION with pause, resume, skip or stop", part)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return Ramps{}, fmt.Errorf("invalid ramp %q", part)
+		}
+		*field = d
+	}
+	return r, nil
+}
+
+// ramp slides its gain to target over the next left samples and then
+// runs done. Like the rest of the stream chain it is only touched with
+// the speaker locked.
+type ramp struct {
+	beep.Streamer
+	gain   float64
+	target float64
+	step   float64
+	left   int
+	done   func()
+	// stopping is set once the song is being faded out to stop, when
+	// reaching its end no longer counts as finishing it.
+	stopping bool
+	// stopped ends the stream, so the mixer drops it.
+	stopped bool
+	// ended is set once the song has played to its end and the mixer
+	// dropped it.
+	ended bool
+}
+
+func (r *ramp) Stream(samples [][2]float64) (int, bool) {
+	if r.stopped {
+		return 0, false
+	}
+	if r.left > 0 && r.left < len(samples) {
+		// Finish the ramp first so done, which may pause, lands on the
+		// exact sample.
+		n, ok := r.Stream(samples[:r.left])
+		if !ok {
+			return n, ok
+		}
+		rest, ok := r.Stream(samples[n:])
+		return n + rest, ok
+	}
+
+	n, ok := r.Streamer.Stream(samples)
+	if !ok {
+		r.ended = true
+	}
+	if r.left == 0 && r.gain == 1 {
+		return n, ok
+	}
+	for i := range samples[:n] {
+		if r.left > 0 {
+			r.gain += r.step
+			r.left--
+			if r.left == 0 {
+				r.gain = r.target
+			}
+		}
+		samples[i][0] *= r.gain
+		samples[i][1] *= r.gain
+	}
+	if r.left == 0 && r.done != nil {
+		done := r.done
+		r.done = nil
+		done()
+	}
+	return n, ok
+}
+
+// slide starts moving the gain to target over d from wherever it is now,
+// replacing any slide still under way. done may be nil.
+func (r *ramp) slide(target float64, d time.Duration, done func()) {
+	r.target = target
+	r.done = done
+	r.left = speakerRate.N(d)
+	if r.left <= 0 {
+		r.gain = target
+		r.left = 0
+		r.step = 0
+		if done != nil {
+			r.done = nil
+			done()
+		}
+		return
+	}
+	r.step = (target - r.gain) / float64(r.left)
+}
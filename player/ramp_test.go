@@ -0,0 +1,102 @@
+package player
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+func TestParseRamps(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		spec string
+		want Ramps
+	}{
+		{"50ms", Ramps{Pause: 50 * ms, Resume: 50 * ms, Skip: 50 * ms, Stop: 50 * ms}},
+		{"0", Ramps{}},
+		{"pause=200ms", Ramps{Pause: 200 * ms, Resume: 100 * ms, Skip: 30 * ms, Stop: 50 * ms}},
+		{"skip=0, stop=1s", Ramps{Pause: 100 * ms, Resume: 100 * ms, Skip: 0, Stop: time.Second}},
+		{"resume=10ms,resume=20ms", Ramps{Pause: 100 * ms, Resume: 20 * ms, Skip: 30 * ms, Stop: 50 * ms}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRamps(tt.spec)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRamps(%q) = %+v, %v, want %+v", tt.spec, got, err, tt.want)
+		}
+	}
+	for _, spec := range []string{"", "-5ms", "fast", "pause", "pause=", "pause=-1ms", "pause=1x", "fade=1s", "pause=1s,"} {
+		if got, err := ParseRamps(spec); err == nil {
+			t.Errorf("ParseRamps(%q) = %+v, want an error", spec, got)
+		}
+	}
+}
+
+func ones() beep.Streamer {
+	return beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		for i := range samples {
+			samples[i] = [2]float64{1, 1}
+		}
+		return len(samples), true
+	})
+}
+
+func TestRamp(t *testing.T) {
+	speakerRate = 1000
+	defer func() { speakerRate = 0 }()
+
+	r := &ramp{Streamer: ones(), gain: 1}
+	samples := make([][2]float64, 6)
+	for i := range samples {
+		samples[i] = [2]float64{-1, -1}
+	}
+	var done int
+	var last, next float64
+	r.slide(0, 4*time.Millisecond, func() {
+		// done runs on the last ramp sample, before the rest is streamed.
+		done++
+		last, next = samples[3][0], samples[4][0]
+	})
+	if n, ok := r.Stream(samples); n != 6 || !ok {
+		t.Fatalf("Stream = %d, %v", n, ok)
+	}
+	want := []float64{0.75, 0.5, 0.25, 0, 0, 0}
+	for i, s := range samples {
+		if s[0] != want[i] || s[1] != want[i] {
+			t.Errorf("sample %d = %v, want %v", i, s, want[i])
+		}
+	}
+	if done != 1 || last != 0 || next != -1 {
+		t.Errorf("done ran %d times, with the samples around it at %v and %v", done, last, next)
+	}
+
+	// A zero-length slide jumps and runs done at once.
+	called := false
+	r.slide(1, 0, func() { called = true })
+	if !called || r.gain != 1 || r.left != 0 {
+		t.Errorf("zero slide: called %v, gain %v, left %d", called, r.gain, r.left)
+	}
+	r.Stream(samples)
+	if samples[0][0] != 1 {
+		t.Errorf("sample after zero slide = %v, want 1", samples[0][0])
+	}
+}
+
+func TestRampStop(t *testing.T) {
+	speakerRate = 1000
+	defer func() { speakerRate = 0 }()
+
+	r := &ramp{Streamer: ones(), gain: 1}
+	r.slide(0, 2*time.Millisecond, func() { r.stopped = true })
+	samples := make([][2]float64, 4)
+	// The mixer gets the faded samples and then drops the stream.
+	if n, ok := r.Stream(samples); n != 2 || ok {
+		t.Errorf("Stream = %d, %v, want 2, false", n, ok)
+	}
+	if samples[0][0] != 0.5 || samples[1][0] != 0 {
+		t.Errorf("faded samples = %v", samples[:2])
+	}
+	if n, ok := r.Stream(samples); n != 0 || ok {
+		t.Errorf("Stream after stopping = %d, %v, want 0, false", n, ok)
+	}
+}
@@ -0,0 +1,60 @@
+package player
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/faiface/beep"
+)
+
+// tapSize is how many of the latest samples are kept for Samples.
+const tapSize = 4096
+
+var (
+	tapping  atomic.Bool
+	tapMutex sync.Mutex
+	tapRing  [tapSize]float64
+	tapPos   int
+)
+
+// tap records what passes through it while tapping is on, mixed down to
+// mono, and otherwise only costs an atomic load per buffer.
+type tap struct {
+	beep.Streamer
+}
+
+func (t tap) Stream(samples [][2]float64) (int, bool) {
+	n, ok := t.Streamer.Stream(samples)
+	if !tapping.Load() {
+		return n, ok
+	}
+	tapMutex.Lock()
+	for _, s := range samples[:n] {
+		tapRing[tapPos] = (s[0] + s[1]) / 2
+		tapPos = (tapPos + 1) % tapSize
+	}
+	tapMutex.Unlock()
+	return n, ok
+}
+
+// SetTap turns recording of the playing samples for Samples on or off.
+func SetTap(on bool) {
+	if tapping.Swap(on) != on && !on {
+		tapMutex.Lock()
+		tapRing = [tapSize]float64{}
+		tapMutex.Unlock()
+	}
+}
+
+// Samples fills buf with the latest samples sent to the speaker, oldest
+// first, and returns how many it filled.
+func Samples(buf []float64) int {
+	n := min(len(buf), tapSize)
+	tapMutex.Lock()
+	defer tapMutex.Unlock()
+	start := (tapPos - n + tapSize) % tapSize
+	for i := range n {
+		buf[i] = tapRing[(start+i)%tapSize]
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package player
+
+import (
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// volume is the playback volume in percent, kept across songs. The
+// speaker holds its lock while streaming, so changes take that lock.
+var volume = 100
+
+// fade is a gain from 0 to 1 on top of the volume. It moves by fadeStep
+// every sample until it reaches fadeTarget, and like the volume it is
+// kept across songs so a fade-out can span the end of one.
+var fade, fadeTarget, fadeStep = 1.0, 1.0, 0.0
+
+// volumeStreamer scales samples by the volume and fade, squared so that
+// steps sound about even.
+type volumeStreamer struct {
+	beep.Streamer
+}
+
+func (v volumeStreamer) Stream(samples [][2]float64) (int, bool) {
+	n, ok := v.Streamer.Stream(samples)
+	if volume == 100 && fade == 1 && fadeTarget == 1 {
+		return n, ok
+	}
+	volumeGain := float64(volume*volume) / 10000
+	for i := range samples[:n] {
+		if fade < fadeTarget {
+			fade = min(fade+fadeStep, fadeTarget)
+		} else if fade > fadeTarget {
+			fade = max(fade-fadeStep, fadeTarget)
+		}
+		gain := volumeGain * fade * fade
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+	return n, ok
+}
+
+// SetVolume sets the volume in percent, from 0 to 100.
+func SetVolume(percent int) {
+	speaker.Lock()
+	volume = min(max(percent, 0), 100)
+	speaker.Unlock()
+}
+
+func Volume() int {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return volume
+}
+
+// FadeTo ramps the gain to level, from 0 to 1, over d of playback. With
+// no song loaded yet the gain changes at once.
+func FadeTo(level float64, d time.Duration) {
+	mutex.Lock()
+	rate := speakerRate
+	mutex.Unlock()
+
+	speaker.Lock()
+	defer speaker.Unlock()
+	fadeTarget = min(max(level, 0), 1)
+	if n := rate.N(d); n > 0 {
+		fadeStep = max(fade-fadeTarget, fadeTarget-fade) / float64(n)
+	} else {
+		fade = fadeTarget
+	}
+}
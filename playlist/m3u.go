@@ -0,0 +1,64 @@
+package playlist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func ParseM3U(r io.Reader, baseDir string) ([]Track, error) {
+	var tracks []Track
+	pending := Track{Duration: -1}
+
+	scanner := bufio.NewScanner(r)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+		if info, ok := strings.CutPrefix(line, "#EXTINF:"); ok {
+			duration, title, _ := strings.Cut(info, ",")
+			// Attributes such as tvg-id="..." may follow the duration.
+			duration, _, _ = strings.Cut(strings.TrimSpace(duration), " ")
+			if d, err := strconv.Atoi(duration); err == nil {
+				pending.Duration = d
+			}
+			pending.Title = strings.TrimSpace(title)
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pending.Path = resolve(line, baseDir)
+		tracks = append(tracks, pending)
+		pending = Track{Duration: -1}
+	}
+	return tracks, scanner.Err()
+}
+
+func WriteM3U(w io.Writer, tracks []Track, baseDir string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#EXTM3U")
+	for _, t := range tracks {
+		if t.Title != "" || t.Duration >= 0 {
+			title := t.Title
+			if title == "" {
+				title = strings.TrimSuffix(filepath.Base(t.Path), filepath.Ext(t.Path))
+			}
+			fmt.Fprintf(bw, "#EXTINF:%d,%s\n", t.Duration, title)
+		}
+		fmt.Fprintln(bw, relative(t.Path, baseDir))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,146 @@
+package playlist
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Track struct {
+	Path     string
+	Title    string
+	Duration int
+}
+
+func IsPlaylist(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".m3u", ".m3u8", ".pls", ".xspf":
+		return true
+	}
+	return false
+}
+
+func Parse(r io.Reader, format, baseDir string) ([]Track, error) {
+	switch strings.ToLower(format) {
+	case ".m3u", ".m3u8":
+		return ParseM3U(r, baseDir)
+	case ".pls":
+		return ParsePLS(r, baseDir)
+	case ".xspf":
+		return ParseXSPF(r, baseDir)
+	}
+	return nil, fmt.Errorf("unsupported playlist format %q", format)
+}
+
+func Write(w io.Writer, tracks []Track, format, baseDir string) error {
+	switch strings.ToLower(format) {
+	case ".m3u", ".m3u8":
+		return WriteM3U(w, tracks, baseDir)
+	case ".pls":
+		return WritePLS(w, tracks, baseDir)
+	case ".xspf":
+		return WriteXSPF(w, tracks, baseDir)
+	}
+	return fmt.Errorf("unsupported playlist format %q", format)
+}
+
+func Load(path string) ([]Track, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, filepath.Ext(path), baseDir(path))
+}
+
+func Save(path string, tracks []Track) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, tracks, filepath.Ext(path), baseDir(path)); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func Convert(in, out string, rebase map[string]string) (int, error) {
+	tracks, err := Load(in)
+	if err != nil {
+		return 0, err
+	}
+	for i := range tracks {
+		tracks[i].Path = Rebase(tracks[i].Path, rebase)
+	}
+	return len(tracks), Save(out, tracks)
+}
+
+func Rebase(path string, rebase map[string]string) string {
+	best := ""
+	for from := range rebase {
+		if len(from) > len(best) && hasPathPrefix(path, from) {
+			best = from
+		}
+	}
+	if best == "" {
+		return path
+	}
+	return filepath.Join(rebase[best], strings.TrimPrefix(path, best))
+}
+
+func hasPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, string(filepath.Separator))
+	return path == prefix || strings.HasPrefix(path, prefix+string(filepath.Separator))
+}
+
+func Paths(tracks []Track) []string {
+	paths := make([]string, len(tracks))
+	for i, t := range tracks {
+		paths[i] = t.Path
+	}
+	return paths
+}
+
+func FromPaths(paths []string) []Track {
+	tracks := make([]Track, len(paths))
+	for i, p := range paths {
+		tracks[i] = Track{Path: p, Duration: -1}
+	}
+	return tracks
+}
+
+func baseDir(path string) string {
+	dir := filepath.Dir(path)
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+func resolve(location, baseDir string) string {
+	if strings.HasPrefix(location, "file://") {
+		if u, err := url.Parse(location); err == nil {
+			location = u.Path
+		}
+	}
+	if strings.Contains(location, "://") {
+		return location
+	}
+	location = filepath.FromSlash(location)
+	if filepath.IsAbs(location) || baseDir == "" {
+		return filepath.Clean(location)
+	}
+	return filepath.Join(baseDir, location)
+}
+
+func relative(path, baseDir string) string {
+	if baseDir == "" || strings.Contains(path, "://") {
+		return path
+	}
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
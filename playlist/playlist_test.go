@@ -0,0 +1,144 @@
+package playlist
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	base := filepath.FromSlash("/music")
+	tracks := []Track{
+		{Path: filepath.FromSlash("/music/Radiohead/01 Airbag.flac"), Title: "Airbag", Duration: 284},
+		{Path: filepath.FromSlash("/music/Björk/Hyperballad #2 (100%).mp3"), Duration: -1},
+		{Path: filepath.FromSlash("/elsewhere/a, b & c.ogg"), Title: "A, B & C", Duration: 61},
+		{Path: "http://radio.example.com/stream", Title: "Radio", Duration: -1},
+	}
+
+	for _, format := range []string{".m3u", ".m3u8", ".pls", ".xspf"} {
+		var buf bytes.Buffer
+		if err := Write(&buf, tracks, format, base); err != nil {
+			t.Fatalf("Write %s: %v", format, err)
+		}
+		got, err := Parse(&buf, format, base)
+		if err != nil {
+			t.Fatalf("Parse %s: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, tracks) {
+			t.Errorf("%s round trip:\n got  %+v\n want %+v", format, got, tracks)
+		}
+	}
+}
+
+func TestWriteRelative(t *testing.T) {
+	base := filepath.FromSlash("/music")
+	tracks := []Track{
+		{Path: filepath.FromSlash("/music/Artist/Song.mp3"), Duration: -1},
+		{Path: filepath.FromSlash("/other/Song.mp3"), Duration: -1},
+	}
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{".m3u", []string{"\nArtist/Song.mp3\n", "\n" + filepath.FromSlash("/other/Song.mp3") + "\n"}},
+		{".pls", []string{"File1=Artist/Song.mp3\n", "File2=" + filepath.FromSlash("/other/Song.mp3") + "\n"}},
+		{".xspf", []string{"<location>Artist/Song.mp3</location>", "<location>file:///other/Song.mp3</location>"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, tracks, tt.format, base); err != nil {
+			t.Fatalf("Write %s: %v", tt.format, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s output lacks %q:\n%s", tt.format, want, buf.String())
+			}
+		}
+	}
+}
+
+func TestParseM3U(t *testing.T) {
+	text := "\ufeff#EXTM3U\r\n" +
+		"#EXTINF:123 tvg-id=\"x\",Artist - Title\r\n" +
+		"Artist/Title.mp3\r\n" +
+		"\r\n" +
+		"# a comment\r\n" +
+		"#EXTINF:-1,\r\n" +
+		"file:///abs/Song%20Two.flac\r\n" +
+		"../up.ogg\r\n"
+	got, err := ParseM3U(strings.NewReader(text), filepath.FromSlash("/music/lists"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Track{
+		{Path: filepath.FromSlash("/music/lists/Artist/Title.mp3"), Title: "Artist - Title", Duration: 123},
+		{Path: filepath.FromSlash("/abs/Song Two.flac"), Duration: -1},
+		{Path: filepath.FromSlash("/music/up.ogg"), Duration: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseM3U:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestParsePLS(t *testing.T) {
+	text := "[playlist]\n" +
+		"File2=b.mp3\n" +
+		"Title2=Second\n" +
+		"file10=c.mp3\n" +
+		"Length10=42\n" +
+		"File1=a.mp3\n" +
+		"Length1=oops\n" +
+		"Title3=Orphan\n" +
+		"NumberOfEntries=3\n" +
+		"Version=2\n"
+	got, err := ParsePLS(strings.NewReader(text), filepath.FromSlash("/music"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Track{
+		{Path: filepath.FromSlash("/music/a.mp3"), Duration: -1},
+		{Path: filepath.FromSlash("/music/b.mp3"), Title: "Second", Duration: -1},
+		{Path: filepath.FromSlash("/music/c.mp3"), Duration: 42},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePLS:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestParseXSPF(t *testing.T) {
+	text := `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <trackList>
+    <track><location>Some%20Artist/Song.mp3</location><title> Song </title><duration>2500</duration></track>
+    <track><title>No location</title></track>
+    <track><location>file:///abs/B%C3%A9b%C3%A9.flac</location></track>
+  </trackList>
+</playlist>
+`
+	got, err := ParseXSPF(strings.NewReader(text), filepath.FromSlash("/music"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Track{
+		{Path: filepath.FromSlash("/music/Some Artist/Song.mp3"), Title: "Song", Duration: 2},
+		{Path: filepath.FromSlash("/abs/Bébé.flac"), Duration: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseXSPF:\n got  %+v\n want %+v", got, want)
+	}
+
+	if _, err := ParseXSPF(strings.NewReader("<playlist"), ""); err == nil {
+		t.Error("ParseXSPF of broken XML succeeded, want an error")
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := Parse(strings.NewReader(""), ".txt", ""); err == nil {
+		t.Error("Parse .txt succeeded, want an error")
+	}
+	if err := Write(&bytes.Buffer{}, nil, ".txt", ""); err == nil {
+		t.Error("Write .txt succeeded, want an error")
+	}
+}
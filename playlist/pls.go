@@ -0,0 +1,94 @@
+package playlist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func ParsePLS(r io.Reader, baseDir string) ([]Track, error) {
+	entries := map[int]*Track{}
+	entry := func(n int) *Track {
+		if t, ok := entries[n]; ok {
+			return t
+		}
+		t := &Track{Duration: -1}
+		entries[n] = t
+		return t
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		var field string
+		switch {
+		case strings.HasPrefix(key, "file"):
+			field = "file"
+		case strings.HasPrefix(key, "title"):
+			field = "title"
+		case strings.HasPrefix(key, "length"):
+			field = "length"
+		default:
+			continue
+		}
+		n, err := strconv.Atoi(key[len(field):])
+		if err != nil {
+			continue
+		}
+
+		t := entry(n)
+		switch field {
+		case "file":
+			t.Path = resolve(value, baseDir)
+		case "title":
+			t.Title = value
+		case "length":
+			if d, err := strconv.Atoi(value); err == nil {
+				t.Duration = d
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	numbers := make([]int, 0, len(entries))
+	for n, t := range entries {
+		if t.Path != "" {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	tracks := make([]Track, 0, len(numbers))
+	for _, n := range numbers {
+		tracks = append(tracks, *entries[n])
+	}
+	return tracks, nil
+}
+
+func WritePLS(w io.Writer, tracks []Track, baseDir string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "[playlist]")
+	for i, t := range tracks {
+		n := i + 1
+		fmt.Fprintf(bw, "File%d=%s\n", n, relative(t.Path, baseDir))
+		if t.Title != "" {
+			fmt.Fprintf(bw, "Title%d=%s\n", n, t.Title)
+		}
+		fmt.Fprintf(bw, "Length%d=%d\n", n, t.Duration)
+	}
+	fmt.Fprintf(bw, "NumberOfEntries=%d\n", len(tracks))
+	fmt.Fprintln(bw, "Version=2")
+	return bw.Flush()
+}
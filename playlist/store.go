@@ -0,0 +1,170 @@
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+const storeFile = "playlists.json"
+
+var (
+	ErrExists   = errors.New("playlist already exists")
+	ErrNotFound = errors.New("playlist not found")
+)
+
+type named struct {
+	Name   string   `json:"name"`
+	Tracks []string `json:"tracks"`
+}
+
+type Store struct {
+	mutex sync.Mutex
+	path  string
+	lists []named
+}
+
+func OpenStore() *Store {
+	s := &Store{path: filepath.Join(state.DataDir(), storeFile)}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return s
+	}
+	_ = json.Unmarshal(data, &s.lists)
+	return s
+}
+
+func (s *Store) Names() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	names := make([]string, len(s.lists))
+	for i, l := range s.lists {
+		names[i] = l.Name
+	}
+	return names
+}
+
+func (s *Store) Tracks(name string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if i := s.index(name); i >= 0 {
+		return slices.Clone(s.lists[i].Tracks)
+	}
+	return nil
+}
+
+func (s *Store) Create(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("playlist name is empty")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.index(name) >= 0 {
+		return ErrExists
+	}
+	s.lists = append(s.lists, named{Name: name})
+	return s.save()
+}
+
+func (s *Store) Rename(name, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return errors.New("playlist name is empty")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	if j := s.index(newName); j >= 0 && j != i {
+		return ErrExists
+	}
+	s.lists[i].Name = newName
+	return s.save()
+}
+
+func (s *Store) Delete(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	s.lists = slices.Delete(s.lists, i, i+1)
+	return s.save()
+}
+
+func (s *Store) Add(name string, paths []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	s.lists[i].Tracks = append(s.lists[i].Tracks, paths...)
+	return s.save()
+}
+
+func (s *Store) Remove(name string, index int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	tracks := s.lists[i].Tracks
+	if index < 0 || index >= len(tracks) {
+		return fmt.Errorf("track %d out of range", index)
+	}
+	s.lists[i].Tracks = slices.Delete(tracks, index, index+1)
+	return s.save()
+}
+
+func (s *Store) Move(name string, from, to int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	tracks := s.lists[i].Tracks
+	if from < 0 || from >= len(tracks) || to < 0 || to >= len(tracks) {
+		return fmt.Errorf("track %d out of range", to)
+	}
+	track := tracks[from]
+	tracks = slices.Delete(tracks, from, from+1)
+	s.lists[i].Tracks = slices.Insert(tracks, to, track)
+	return s.save()
+}
+
+func (s *Store) Export(name, path string) error {
+	tracks := s.Tracks(name)
+	if tracks == nil && !slices.Contains(s.Names(), name) {
+		return ErrNotFound
+	}
+	return Save(path, FromPaths(tracks))
+}
+
+func (s *Store) index(name string) int {
+	return slices.IndexFunc(s.lists, func(l named) bool { return l.Name == name })
+}
+
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.lists, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
@@ -0,0 +1,100 @@
+package playlist
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Version string      `xml:"version,attr"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title,omitempty"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+func ParseXSPF(r io.Reader, baseDir string) ([]Track, error) {
+	var doc xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	tracks := make([]Track, 0, len(doc.Tracks))
+	for _, t := range doc.Tracks {
+		location := strings.TrimSpace(t.Location)
+		if location == "" {
+			continue
+		}
+		duration := -1
+		if t.Duration > 0 {
+			duration = t.Duration / 1000
+		}
+		tracks = append(tracks, Track{
+			Path:     resolve(unescapeLocation(location), baseDir),
+			Title:    strings.TrimSpace(t.Title),
+			Duration: duration,
+		})
+	}
+	return tracks, nil
+}
+
+func WriteXSPF(w io.Writer, tracks []Track, baseDir string) error {
+	doc := xspfPlaylist{Version: "1", Xmlns: "http://xspf.org/ns/0/"}
+	for _, t := range tracks {
+		duration := 0
+		if t.Duration > 0 {
+			duration = t.Duration * 1000
+		}
+		doc.Tracks = append(doc.Tracks, xspfTrack{
+			Location: escapeLocation(relative(t.Path, baseDir)),
+			Title:    t.Title,
+			Duration: duration,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// unescapeLocation turns a relative XSPF URI into a plain path; file:// and
+// other absolute URIs are left for resolve to handle.
+func unescapeLocation(location string) string {
+	if strings.Contains(location, "://") {
+		return location
+	}
+	if p, err := url.PathUnescape(location); err == nil {
+		return p
+	}
+	return location
+}
+
+func escapeLocation(location string) string {
+	if strings.Contains(location, "://") {
+		return location
+	}
+	if filepath.IsAbs(location) {
+		p := filepath.ToSlash(location)
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+		u := url.URL{Scheme: "file", Path: p}
+		return u.String()
+	}
+	return (&url.URL{Path: location}).EscapedPath()
+}
@@ -0,0 +1,259 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Gylmynnn/dicesong/library"
+)
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	numberField
+	boolField
+	ageField
+)
+
+var fields = map[string]fieldKind{
+	"title":       textField,
+	"artist":      textField,
+	"albumartist": textField,
+	"album":       textField,
+	"genre":       textField,
+	"comment":     textField,
+	"ext":         textField,
+	"path":        textField,
+	"name":        textField,
+	"dir":         textField,
+	"year":        numberField,
+	"track":       numberField,
+	"disc":        numberField,
+	"rating":      numberField,
+	"plays":       numberField,
+	"size":        numberField,
+	"favorite":    boolField,
+	"played":      ageField,
+	"added":       ageField,
+	"modified":    ageField,
+}
+
+var operators = []string{"!=", "!~", "<=", ">=", "==", "=", "<", ">", "~"}
+
+type Filter struct {
+	Field  string
+	Op     string
+	Value  string
+	Negate bool
+
+	kind    fieldKind
+	number  float64
+	age     time.Duration
+	boolean bool
+}
+
+func (r *Filter) compile() error {
+	if r.Op == "==" {
+		r.Op = "="
+	}
+	switch r.kind {
+	case textField:
+		r.Value = strings.ToLower(r.Value)
+		if r.Field == "ext" {
+			r.Value = strings.TrimPrefix(r.Value, ".")
+		}
+		return nil
+	case numberField:
+		if r.Op == "~" || r.Op == "!~" {
+			return fmt.Errorf("%s cannot be used with %s", r.Op, r.Field)
+		}
+		n, err := parseNumber(r.Value)
+		if err != nil {
+			return fmt.Errorf("invalid number for %s: %q", r.Field, r.Value)
+		}
+		r.number = n
+	case boolField:
+		if r.Op != "=" && r.Op != "!=" {
+			return fmt.Errorf("%s only supports = and !=", r.Field)
+		}
+		b, err := strconv.ParseBool(strings.ToLower(r.Value))
+		if err != nil {
+			return fmt.Errorf("invalid boolean for %s: %q", r.Field, r.Value)
+		}
+		r.boolean = b
+	case ageField:
+		if r.Op == "~" || r.Op == "!~" {
+			return fmt.Errorf("%s cannot be used with %s", r.Op, r.Field)
+		}
+		d, err := ParseAge(r.Value)
+		if err != nil {
+			return fmt.Errorf("invalid age for %s: %q", r.Field, r.Value)
+		}
+		r.age = d
+	}
+	return nil
+}
+
+func (r Filter) Match(t library.Track, now time.Time) bool {
+	return r.match(t, now) != r.Negate
+}
+
+func (r Filter) match(t library.Track, now time.Time) bool {
+	switch r.kind {
+	case textField:
+		value := strings.ToLower(textValue(t, r.Field))
+		switch r.Op {
+		case "=":
+			return value == r.Value
+		case "!=":
+			return value != r.Value
+		case "~":
+			return strings.Contains(value, r.Value)
+		case "!~":
+			return !strings.Contains(value, r.Value)
+		}
+		return compare(strings.Compare(value, r.Value), r.Op)
+	case numberField:
+		return compareFloat(numberValue(t, r.Field), r.number, r.Op)
+	case boolField:
+		return (t.Favorite == r.boolean) == (r.Op == "=")
+	case ageField:
+		age := time.Duration(math.MaxInt64)
+		if ts := timeValue(t, r.Field); !ts.IsZero() {
+			age = now.Sub(ts)
+		}
+		return compareFloat(float64(age), float64(r.age), r.Op)
+	}
+	return false
+}
+
+func textValue(t library.Track, field string) string {
+	switch field {
+	case "title":
+		return t.Tags.Title
+	case "artist":
+		return t.Tags.Artist
+	case "albumartist":
+		return t.Tags.AlbumArtist
+	case "album":
+		return t.Tags.Album
+	case "genre":
+		return t.Tags.Genre
+	case "comment":
+		return t.Tags.Comment
+	case "ext":
+		return t.Ext
+	case "path":
+		return t.Rel
+	case "name":
+		return filepath.Base(t.Path)
+	case "dir":
+		return filepath.Dir(t.Rel)
+	}
+	return ""
+}
+
+func numberValue(t library.Track, field string) float64 {
+	switch field {
+	case "year":
+		return float64(t.Tags.Year)
+	case "track":
+		return float64(t.Tags.Track)
+	case "disc":
+		return float64(t.Tags.Disc)
+	case "rating":
+		return float64(t.Rating)
+	case "plays":
+		return float64(t.Plays)
+	case "size":
+		return float64(t.Size)
+	}
+	return 0
+}
+
+func timeValue(t library.Track, field string) time.Time {
+	switch field {
+	case "played":
+		return t.LastPlayed
+	case "added":
+		return t.Added
+	case "modified":
+		return t.ModTime
+	}
+	return time.Time{}
+}
+
+func compareFloat(a, b float64, op string) bool {
+	switch {
+	case a < b:
+		return compare(-1, op)
+	case a > b:
+		return compare(1, op)
+	}
+	return compare(0, op)
+}
+
+func compare(c int, op string) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func parseNumber(s string) (float64, error) {
+	lower := strings.ToLower(s)
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10}, {"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}} {
+		if strings.HasSuffix(lower, unit.suffix) {
+			lower = strings.TrimSuffix(lower, unit.suffix)
+			multiplier = unit.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(lower, 64)
+	return n * multiplier, err
+}
+
+// ParseAge accepts durations like 90s, 12h, 30d, 2w, 6mo and 1y.
+func ParseAge(s string) (time.Duration, error) {
+	lower := strings.ToLower(s)
+	end := strings.IndexFunc(lower, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+	if end <= 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	n, err := strconv.ParseFloat(lower[:end], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	day := 24 * time.Hour
+	units := map[string]time.Duration{
+		"s": time.Second, "min": time.Minute, "h": time.Hour,
+		"d": day, "w": 7 * day, "mo": 30 * day, "y": 365 * day,
+	}
+	unit, ok := units[lower[end:]]
+	if !ok {
+		return 0, fmt.Errorf("invalid age unit in %q", s)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
@@ -0,0 +1,224 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/library"
+)
+
+type Term struct {
+	Text   string
+	Phrase bool
+	Negate bool
+}
+
+type Query struct {
+	Filters []Filter
+	Terms   []Term
+}
+
+// Parse understands field filters such as `artist:radiohead`, `year:>2000`
+// or `rating >= 4`, negation with a leading `-`, `!` or `not`, quoted
+// phrases and bare words. All parts must match. Text fields compare by
+// substring in the `field:value` form and exactly with an explicit `=`.
+func Parse(expr string) (Query, error) {
+	var q Query
+	s := scanner{src: expr}
+	negateNext := false
+
+	for {
+		s.skipSpace()
+		if s.done() {
+			return q, nil
+		}
+
+		negate := negateNext
+		negateNext = false
+		if c := s.peek(); (c == '-' || c == '!') && s.pos+1 < len(s.src) && s.src[s.pos+1] != ' ' {
+			negate = true
+			s.pos++
+		}
+
+		if c := s.peek(); c == '"' || c == '\'' {
+			phrase, err := s.quoted()
+			if err != nil {
+				return Query{}, err
+			}
+			q.Terms = append(q.Terms, Term{Text: phrase, Phrase: true, Negate: negate})
+			continue
+		}
+
+		start := s.pos
+		word := s.word(":=!<>~")
+		field := strings.ToLower(word)
+		kind, known := fields[field]
+
+		switch {
+		case known && s.peek() == ':':
+			s.pos++
+			op := s.operator()
+			if op == "" {
+				op = defaultOp(kind)
+			}
+			value, err := s.value()
+			if err != nil {
+				return Query{}, err
+			}
+			if value == "" {
+				return Query{}, fmt.Errorf("missing value for %s", field)
+			}
+			f := Filter{Field: field, Op: op, Value: value, Negate: negate, kind: kind}
+			if err := f.compile(); err != nil {
+				return Query{}, err
+			}
+			q.Filters = append(q.Filters, f)
+			continue
+		case known && s.nextIsOperator():
+			s.skipSpace()
+			op := s.operator()
+			s.skipSpace()
+			value, err := s.value()
+			if err != nil {
+				return Query{}, err
+			}
+			if value == "" {
+				return Query{}, fmt.Errorf("missing value after %s %s", field, op)
+			}
+			f := Filter{Field: field, Op: op, Value: value, Negate: negate, kind: kind}
+			if err := f.compile(); err != nil {
+				return Query{}, err
+			}
+			q.Filters = append(q.Filters, f)
+			continue
+		case known && kind == boolField:
+			f := Filter{Field: field, Op: "=", Value: "true", Negate: negate, kind: kind}
+			if err := f.compile(); err != nil {
+				return Query{}, err
+			}
+			q.Filters = append(q.Filters, f)
+			continue
+		case !negate && field == "and":
+			continue
+		case !negate && field == "not" && !s.done():
+			negateNext = true
+			continue
+		}
+
+		// Anything else, including words like "Re:Stacks", is free text.
+		s.pos = start
+		text := s.word("")
+		if text == "" {
+			return Query{}, fmt.Errorf("unexpected %q", s.src[s.pos:])
+		}
+		q.Terms = append(q.Terms, Term{Text: text, Negate: negate})
+	}
+}
+
+func (q Query) Empty() bool {
+	return len(q.Filters) == 0 && len(q.Terms) == 0
+}
+
+func (q Query) MatchFilters(t library.Track, now time.Time) bool {
+	for _, f := range q.Filters {
+		if !f.Match(t, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match checks the filters and requires each term to appear as a substring
+// of the relative path or the title, artist or album tags.
+func (q Query) Match(t library.Track, now time.Time) bool {
+	if !q.MatchFilters(t, now) {
+		return false
+	}
+	haystack := strings.ToLower(strings.Join([]string{t.Rel, t.Tags.Title, t.Tags.Artist, t.Tags.Album}, "\n"))
+	for _, term := range q.Terms {
+		if strings.Contains(haystack, strings.ToLower(term.Text)) == term.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func defaultOp(kind fieldKind) string {
+	switch kind {
+	case textField:
+		return "~"
+	case ageField:
+		return "<"
+	}
+	return "="
+}
+
+type scanner struct {
+	src string
+	pos int
+}
+
+func (s *scanner) done() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *scanner) peek() byte {
+	if s.done() {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+func (s *scanner) skipSpace() {
+	for !s.done() && (s.src[s.pos] == ' ' || s.src[s.pos] == '\t') {
+		s.pos++
+	}
+}
+
+func (s *scanner) word(stops string) string {
+	start := s.pos
+	for !s.done() {
+		c := s.src[s.pos]
+		if c == ' ' || c == '\t' || c == '"' || strings.IndexByte(stops, c) >= 0 {
+			break
+		}
+		s.pos++
+	}
+	return s.src[start:s.pos]
+}
+
+func (s *scanner) quoted() (string, error) {
+	quote := s.src[s.pos]
+	end := strings.IndexByte(s.src[s.pos+1:], quote)
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quote in %q", s.src)
+	}
+	text := s.src[s.pos+1 : s.pos+1+end]
+	s.pos += end + 2
+	return text, nil
+}
+
+func (s *scanner) value() (string, error) {
+	if c := s.peek(); c == '"' || c == '\'' {
+		return s.quoted()
+	}
+	return s.word(""), nil
+}
+
+func (s *scanner) operator() string {
+	for _, op := range operators {
+		if strings.HasPrefix(s.src[s.pos:], op) {
+			s.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+func (s *scanner) nextIsOperator() bool {
+	saved := s.pos
+	defer func() { s.pos = saved }()
+	s.skipSpace()
+	return s.operator() != ""
+}
@@ -0,0 +1,184 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/library"
+	"github.com/Gylmynnn/dicesong/tags"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		filters []string
+		terms   []Term
+	}{
+		{"", nil, nil},
+		{"abbey road", nil, []Term{{Text: "abbey"}, {Text: "road"}}},
+		{"-live", nil, []Term{{Text: "live", Negate: true}}},
+		{"!live", nil, []Term{{Text: "live", Negate: true}}},
+		{"not live", nil, []Term{{Text: "live", Negate: true}}},
+		{"live not", nil, []Term{{Text: "live"}, {Text: "not"}}},
+		{"a -", nil, []Term{{Text: "a"}, {Text: "-"}}},
+		{`"ok computer"`, nil, []Term{{Text: "ok computer", Phrase: true}}},
+		{`-'ok computer'`, nil, []Term{{Text: "ok computer", Phrase: true, Negate: true}}},
+		{"Re:Stacks", nil, []Term{{Text: "Re:Stacks"}}},
+		{"artist:radiohead", []string{"artist ~ radiohead"}, nil},
+		{"ARTIST:Radiohead", []string{"artist ~ radiohead"}, nil},
+		{"artist:=Radiohead", []string{"artist = radiohead"}, nil},
+		{`album:"ok computer"`, []string{"album ~ ok computer"}, nil},
+		{"year:>2000", []string{"year > 2000"}, nil},
+		{"year >= 2000 and rating == 4", []string{"year >= 2000", "rating = 4"}, nil},
+		{"-genre:pop", []string{"-genre ~ pop"}, nil},
+		{"not genre:pop", []string{"-genre ~ pop"}, nil},
+		{"ext:.FLAC", []string{"ext ~ flac"}, nil},
+		{"played:30d", []string{"played < 30d"}, nil},
+		{"favorite", []string{"favorite = true"}, nil},
+		{"favorite:false", []string{"favorite = false"}, nil},
+		{"size:>10m flac", []string{"size > 10m"}, []Term{{Text: "flac"}}},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		var filters []string
+		for _, f := range q.Filters {
+			s := f.Field + " " + f.Op + " " + f.Value
+			if f.Negate {
+				s = "-" + s
+			}
+			filters = append(filters, s)
+		}
+		if !reflect.DeepEqual(filters, tt.filters) {
+			t.Errorf("Parse(%q) filters = %q, want %q", tt.expr, filters, tt.filters)
+		}
+		if !reflect.DeepEqual(q.Terms, tt.terms) {
+			t.Errorf("Parse(%q) terms = %+v, want %+v", tt.expr, q.Terms, tt.terms)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		`"unterminated`,
+		"artist:",
+		"year >=",
+		"year:abc",
+		"year:~2000",
+		"favorite:maybe",
+		"favorite:<true",
+		"played:10x",
+		"played:~1d",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	track := library.Track{
+		Path:       "/music/Radiohead/OK Computer/02 Paranoid Android.flac",
+		Rel:        "Radiohead/OK Computer/02 Paranoid Android.flac",
+		Ext:        "flac",
+		Size:       40 << 20,
+		Added:      now.Add(-3 * 24 * time.Hour),
+		LastPlayed: now.Add(-60 * 24 * time.Hour),
+		Tags: tags.Tags{
+			Title:  "Paranoid Android",
+			Artist: "Radiohead",
+			Album:  "OK Computer",
+			Genre:  "Alternative Rock",
+			Year:   1997,
+			Track:  2,
+		},
+		Rating:   4,
+		Favorite: true,
+		Plays:    12,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"paranoid", true},
+		{"PARANOID android", true},
+		{"creep", false},
+		{"-creep", true},
+		{"-paranoid", false},
+		{`"ok computer"`, true},
+		{`"computer ok"`, false},
+		{"artist:radio", true},
+		{"artist:=radio", false},
+		{"artist:=radiohead", true},
+		{"artist!=radiohead", false},
+		{"genre!~pop", true},
+		{"-genre:rock", false},
+		{"ext:flac", true},
+		{"ext:.mp3", false},
+		{"name:android", true},
+		{"dir:computer", true},
+		{"year:1997", true},
+		{"year:>2000", false},
+		{"year >= 1990 and year < 2000", true},
+		{"track:2 disc:0", true},
+		{"rating:>=4", true},
+		{"rating:5", false},
+		{"plays:>10", true},
+		{"size:>30m", true},
+		{"size:<1g", true},
+		{"favorite", true},
+		{"favorite:false", false},
+		{"not favorite", false},
+		{"added:7d", true},
+		{"added:>7d", false},
+		{"played:>30d", true},
+		{"played:<30d", false},
+		{"modified:<1y", false},
+		{"modified:>1y", true},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := q.Match(track, now); got != tt.want {
+			t.Errorf("%q matched = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"90s", 90 * time.Second},
+		{"5min", 5 * time.Minute},
+		{"12h", 12 * time.Hour},
+		{"30d", 30 * day},
+		{"1.5d", 36 * time.Hour},
+		{"2w", 14 * day},
+		{"6mo", 180 * day},
+		{"1Y", 365 * day},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "d", "10", "10m", "-1d"} {
+		if _, err := ParseAge(in); err == nil {
+			t.Errorf("ParseAge(%q) succeeded, want an error", in)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package ratings
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+const (
+	dbFile    = "ratings.json"
+	MaxRating = 5
+	hashBytes = 64 * 1024
+)
+
+type Entry struct {
+	Hash     string `json:"hash"`
+	Rating   int    `json:"rating"`
+	Favorite bool   `json:"favorite"`
+}
+
+type DB struct {
+	mutex   sync.Mutex
+	path    string
+	entries map[string]Entry
+}
+
+func Open() *DB {
+	db := &DB{
+		path:    filepath.Join(state.DataDir(), dbFile),
+		entries: map[string]Entry{},
+	}
+	data, err := os.ReadFile(db.path)
+	if err != nil {
+		return db
+	}
+	_ = json.Unmarshal(data, &db.entries)
+	return db
+}
+
+func (db *DB) Get(path string) Entry {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.entries[path]
+}
+
+func (db *DB) SetRating(path string, rating int) (Entry, error) {
+	rating = min(max(rating, 0), MaxRating)
+	return db.update(path, func(e *Entry) { e.Rating = rating })
+}
+
+func (db *DB) ToggleFavorite(path string) (Entry, error) {
+	return db.update(path, func(e *Entry) { e.Favorite = !e.Favorite })
+}
+
+// Relink moves entries whose file has disappeared onto a song in the
+// library with the same content hash, so renames and moves keep ratings.
+func (db *DB) Relink(songs []string) {
+	db.mutex.Lock()
+	missing := map[string]string{}
+	for path, entry := range db.entries {
+		if entry.Hash == "" {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			missing[entry.Hash] = path
+		}
+	}
+	db.mutex.Unlock()
+	if len(missing) == 0 {
+		return
+	}
+
+	sizes := map[string]bool{}
+	for hash := range missing {
+		size, _, _ := strings.Cut(hash, "-")
+		sizes[size] = true
+	}
+
+	changed := false
+	for _, song := range songs {
+		info, err := os.Stat(song)
+		if err != nil || !sizes[fmt.Sprint(info.Size())] {
+			continue
+		}
+		hash, err := fileHash(song)
+		if err != nil {
+			continue
+		}
+		oldPath, ok := missing[hash]
+		if !ok {
+			continue
+		}
+
+		db.mutex.Lock()
+		if _, taken := db.entries[song]; !taken {
+			db.entries[song] = db.entries[oldPath]
+			delete(db.entries, oldPath)
+			changed = true
+		}
+		db.mutex.Unlock()
+		delete(missing, hash)
+	}
+
+	if changed {
+		db.mutex.Lock()
+		_ = db.save()
+		db.mutex.Unlock()
+	}
+}
+
+func (db *DB) update(path string, fn func(*Entry)) (Entry, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	entry := db.entries[path]
+	if entry.Hash == "" {
+		hash, err := fileHash(path)
+		if err != nil {
+			return entry, err
+		}
+		entry.Hash = hash
+	}
+	fn(&entry)
+
+	if entry.Rating == 0 && !entry.Favorite {
+		delete(db.entries, path)
+	} else {
+		db.entries[path] = entry
+	}
+	return entry, db.save()
+}
+
+func (db *DB) save() error {
+	data, err := json.MarshalIndent(db.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(db.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(db.path, data, 0o644)
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha1.New()
+	if _, err := io.CopyN(h, f, hashBytes); err != nil && err != io.EOF {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", info.Size(), hex.EncodeToString(h.Sum(nil))), nil
+}
+
+func Stars(rating int) string {
+	rating = min(max(rating, 0), MaxRating)
+	return strings.Repeat("★", rating) + strings.Repeat("☆", MaxRating-rating)
+}
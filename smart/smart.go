@@ -0,0 +1,164 @@
+package smart
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/library"
+	"github.com/Gylmynnn/dicesong/query"
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+const storeFile = "smart.json"
+
+var (
+	ErrExists   = errors.New("smart playlist already exists")
+	ErrNotFound = errors.New("smart playlist not found")
+)
+
+type Playlist struct {
+	Name  string `json:"name"`
+	Rules string `json:"rules"`
+}
+
+type Store struct {
+	mutex sync.Mutex
+	path  string
+	lists []Playlist
+}
+
+func OpenStore() *Store {
+	s := &Store{path: filepath.Join(state.DataDir(), storeFile)}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return s
+	}
+	_ = json.Unmarshal(data, &s.lists)
+	return s
+}
+
+func (s *Store) Names() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	names := make([]string, len(s.lists))
+	for i, l := range s.lists {
+		names[i] = l.Name
+	}
+	return names
+}
+
+func (s *Store) Rules(name string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if i := s.index(name); i >= 0 {
+		return s.lists[i].Rules
+	}
+	return ""
+}
+
+func (s *Store) Create(name, rules string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("playlist name is empty")
+	}
+	if err := validate(rules); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.index(name) >= 0 {
+		return ErrExists
+	}
+	s.lists = append(s.lists, Playlist{Name: name, Rules: rules})
+	return s.save()
+}
+
+func (s *Store) SetRules(name, rules string) error {
+	if err := validate(rules); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	s.lists[i].Rules = rules
+	return s.save()
+}
+
+func (s *Store) Rename(name, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return errors.New("playlist name is empty")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	if j := s.index(newName); j >= 0 && j != i {
+		return ErrExists
+	}
+	s.lists[i].Name = newName
+	return s.save()
+}
+
+func (s *Store) Delete(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	s.lists = slices.Delete(s.lists, i, i+1)
+	return s.save()
+}
+
+func Evaluate(ix *library.Index, rules string) ([]string, error) {
+	q, err := query.Parse(rules)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var paths []string
+	for _, t := range ix.Tracks() {
+		if q.Match(t, now) {
+			paths = append(paths, t.Path)
+		}
+	}
+	return paths, nil
+}
+
+func validate(rules string) error {
+	q, err := query.Parse(rules)
+	if err != nil {
+		return err
+	}
+	if q.Empty() {
+		return errors.New("no rules given")
+	}
+	return nil
+}
+
+func (s *Store) index(name string) int {
+	return slices.IndexFunc(s.lists, func(l Playlist) bool { return l.Name == name })
+}
+
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.lists, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
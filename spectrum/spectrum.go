@@ -0,0 +1,92 @@
+package spectrum
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	minFrequency = 40
+	maxFrequency = 16000
+	// floorDB is the level shown as an empty band.
+	floorDB = -60
+)
+
+// Bands returns the loudness of n bands spaced evenly in pitch from the
+// lowest to the highest audible frequencies, each from 0 to 1. The number
+// of samples is rounded down to a power of two.
+func Bands(samples []float64, sampleRate, n int) []float64 {
+	bands := make([]float64, n)
+	size := 1
+	for size*2 <= len(samples) {
+		size *= 2
+	}
+	if n == 0 || size < 2 || sampleRate <= 0 {
+		return bands
+	}
+
+	window := make([]complex128, size)
+	for i, s := range samples[len(samples)-size:] {
+		hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size-1))
+		window[i] = complex(s*hann, 0)
+	}
+	fft(window)
+
+	binWidth := float64(sampleRate) / float64(size)
+	low := float64(minFrequency)
+	high := min(float64(maxFrequency), float64(sampleRate)/2)
+	for i := range bands {
+		f0 := low * math.Pow(high/low, float64(i)/float64(n))
+		f1 := low * math.Pow(high/low, float64(i+1)/float64(n))
+		k0 := max(int(f0/binWidth), 1)
+		k1 := min(max(int(f1/binWidth), k0+1), size/2)
+
+		peak := 0.0
+		for k := k0; k < k1; k++ {
+			peak = max(peak, cmplx.Abs(window[k]))
+		}
+		// A full scale sine under a Hann window peaks at size/4.
+		db := 20 * math.Log10(peak/(float64(size)/4)+1e-12)
+		bands[i] = min(max((db-floorDB)/-floorDB, 0), 1)
+	}
+	return bands
+}
+
+// Scope resamples the waveform to n points between -1 and 1.
+func Scope(samples []float64, n int) []float64 {
+	points := make([]float64, n)
+	if len(samples) == 0 {
+		return points
+	}
+	for i := range points {
+		points[i] = min(max(samples[i*len(samples)/n], -1), 1)
+	}
+	return points
+}
+
+// fft transforms x in place. Its length must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := range size / 2 {
+				even, odd := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = even+odd, even-odd
+				w *= step
+			}
+		}
+	}
+}
@@ -3,12 +3,27 @@ package state
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"runtime"
 )
 
 type AppState struct {
-	CurrentSong int  `json:"current_song"`
-	Repeat      bool `json:"repeat"`
-	Shuffle     bool `json:"shuffle"`
+	CurrentSong int                  `json:"current_song"`
+	Repeat      bool                 `json:"repeat"`
+	Shuffle     bool                 `json:"shuffle"`
+	Sorts       map[string]SortOrder `json:"sorts,omitempty"`
+	HideColumns bool                 `json:"hide_columns,omitempty"`
+	Theme       string               `json:"theme,omitempty"`
+	Panes       []string             `json:"panes,omitempty"`
+	PaneWidth   int                  `json:"pane_width,omitempty"`
+	Visualizer  string               `json:"visualizer,omitempty"`
+	Waveform    bool                 `json:"waveform,omitempty"`
+}
+
+// SortOrder is the browser sort order chosen for a directory.
+type SortOrder struct {
+	Mode string `json:"mode"`
+	Desc bool   `json:"desc,omitempty"`
 }
 
 const stateFile = "state.json"
@@ -30,3 +45,26 @@ func Load() AppState {
 	_ = json.Unmarshal(data, &state)
 	return state
 }
+
+func DataDir() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, "dicesong")
+		}
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "dicesong")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(home, ".local", "share", "dicesong")
+}
+
+func ConfigDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "dicesong")
+	}
+	return "."
+}
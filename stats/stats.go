@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+const dbFile = "stats.json"
+
+type Entry struct {
+	Plays      int       `json:"plays"`
+	LastPlayed time.Time `json:"last_played"`
+}
+
+type DB struct {
+	mutex   sync.Mutex
+	path    string
+	entries map[string]Entry
+}
+
+func Open() *DB {
+	db := &DB{
+		path:    filepath.Join(state.DataDir(), dbFile),
+		entries: map[string]Entry{},
+	}
+	data, err := os.ReadFile(db.path)
+	if err != nil {
+		return db
+	}
+	_ = json.Unmarshal(data, &db.entries)
+	return db
+}
+
+func (db *DB) Get(path string) Entry {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.entries[path]
+}
+
+func (db *DB) RecordPlay(path string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	entry := db.entries[path]
+	entry.Plays++
+	entry.LastPlayed = time.Now()
+	db.entries[path] = entry
+
+	data, err := json.MarshalIndent(db.entries, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(db.path), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(db.path, data, 0o644)
+}
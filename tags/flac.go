@@ -0,0 +1,194 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	flacStreamInfo    = 0
+	flacVorbisComment = 4
+)
+
+var errInvalidFLAC = errors.New("invalid FLAC metadata")
+
+type flacBlock struct {
+	kind byte
+	body []byte
+}
+
+// readFLACBlocks reads the metadata blocks and returns how many bytes they
+// occupy. Blocks rejected by keep are skipped and have a nil body.
+func readFLACBlocks(r io.Reader, keep func(kind byte) bool) ([]flacBlock, int, error) {
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != "fLaC" {
+		return nil, 0, errInvalidFLAC
+	}
+
+	var blocks []flacBlock
+	pos := 4
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, 0, errInvalidFLAC
+		}
+		kind := header[0] & 0x7f
+		length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		pos += 4 + length
+
+		block := flacBlock{kind: kind}
+		if keep(kind) {
+			block.body = make([]byte, length)
+			if _, err := io.ReadFull(r, block.body); err != nil {
+				return nil, 0, errInvalidFLAC
+			}
+		} else if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+			return nil, 0, errInvalidFLAC
+		}
+		blocks = append(blocks, block)
+
+		if header[0]&0x80 != 0 {
+			return blocks, pos, nil
+		}
+	}
+}
+
+func readFLAC(r io.Reader) (Tags, error) {
+	var t Tags
+	blocks, _, err := readFLACBlocks(r, func(kind byte) bool { return kind == flacVorbisComment })
+	if err != nil {
+		return t, err
+	}
+	for _, block := range blocks {
+		if block.kind != flacVorbisComment {
+			continue
+		}
+		_, comments, err := parseVorbisComment(block.body)
+		if err != nil {
+			return t, err
+		}
+		t.setComments(comments)
+	}
+	return t, nil
+}
+
+func (t *Tags) setComments(comments []string) {
+	for _, comment := range comments {
+		key, value, ok := strings.Cut(comment, "=")
+		if ok && !slices.Contains(pictureKeys, strings.ToUpper(key)) {
+			t.set(strings.ToUpper(key), value)
+		}
+	}
+}
+
+func writeFLACRating(path string, rating int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	blocks, audioStart, err := readFLACBlocks(bytes.NewReader(data), func(byte) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	vendor := "dicesong"
+	var comments []string
+	for i, block := range blocks {
+		if block.kind != flacVorbisComment {
+			continue
+		}
+		index = i
+		vendor, comments, err = parseVorbisComment(block.body)
+		if err != nil {
+			return err
+		}
+		break
+	}
+
+	block := flacBlock{kind: flacVorbisComment, body: buildVorbisComment(vendor, setRating(comments, rating))}
+	if index >= 0 {
+		blocks[index] = block
+	} else {
+		blocks = append(blocks[:1], append([]flacBlock{block}, blocks[1:]...)...)
+	}
+
+	out := []byte("fLaC")
+	for i, b := range blocks {
+		header := b.kind
+		if i == len(blocks)-1 {
+			header |= 0x80
+		}
+		n := len(b.body)
+		out = append(out, header, byte(n>>16), byte(n>>8), byte(n))
+		out = append(out, b.body...)
+	}
+	out = append(out, data[audioStart:]...)
+	return writeFile(path, out)
+}
+
+// setRating replaces the RATING comments with one on the 0-100 scale, or
+// drops them when rating is 0.
+func setRating(comments []string, rating int) []string {
+	var kept []string
+	for _, comment := range comments {
+		key, _, _ := strings.Cut(comment, "=")
+		if !strings.EqualFold(key, "RATING") {
+			kept = append(kept, comment)
+		}
+	}
+	if rating > 0 {
+		kept = append(kept, fmt.Sprintf("RATING=%d", rating*20))
+	}
+	return kept
+}
+
+func parseVorbisComment(body []byte) (string, []string, error) {
+	readString := func() (string, bool) {
+		if len(body) < 4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(body))
+		if n < 0 || 4+n > len(body) {
+			return "", false
+		}
+		s := string(body[4 : 4+n])
+		body = body[4+n:]
+		return s, true
+	}
+
+	vendor, ok := readString()
+	if !ok || len(body) < 4 {
+		return "", nil, errInvalidFLAC
+	}
+	count := int(binary.LittleEndian.Uint32(body))
+	body = body[4:]
+
+	var comments []string
+	for range count {
+		comment, ok := readString()
+		if !ok {
+			return "", nil, errInvalidFLAC
+		}
+		comments = append(comments, comment)
+	}
+	return vendor, comments, nil
+}
+
+func buildVorbisComment(vendor string, comments []string) []byte {
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(vendor)))
+	out = append(out, vendor...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(comments)))
+	for _, comment := range comments {
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(comment)))
+		out = append(out, comment...)
+	}
+	return out
+}
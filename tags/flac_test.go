@@ -0,0 +1,88 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+)
+
+// flacMeta encodes one metadata block header and body.
+func flacMeta(kind byte, last bool, body []byte) []byte {
+	if last {
+		kind |= 0x80
+	}
+	n := len(body)
+	return append([]byte{kind, byte(n >> 16), byte(n >> 8), byte(n)}, body...)
+}
+
+func TestWriteFLACRating(t *testing.T) {
+	frames := []byte("\xff\xf8audio")
+	streamInfo := make([]byte, 34)
+	padding := make([]byte, 8)
+	comment := func(comments ...string) []byte {
+		return buildVorbisComment("reference libFLAC", comments)
+	}
+	file := func(blocks ...[]byte) []byte {
+		return append(append([]byte("fLaC"), bytes.Join(blocks, nil)...), frames...)
+	}
+
+	tests := []struct {
+		name   string
+		file   []byte
+		rating int
+		want   []byte
+	}{
+		{
+			name: "replaces RATING comments",
+			file: file(
+				flacMeta(flacStreamInfo, false, streamInfo),
+				flacMeta(flacVorbisComment, false, comment("TITLE=Song", "rating=60", "RATING=40")),
+				flacMeta(1, true, padding),
+			),
+			rating: 5,
+			want: file(
+				flacMeta(flacStreamInfo, false, streamInfo),
+				flacMeta(flacVorbisComment, false, comment("TITLE=Song", "RATING=100")),
+				flacMeta(1, true, padding),
+			),
+		},
+		{
+			name:   "adds a comment block after STREAMINFO",
+			file:   file(flacMeta(flacStreamInfo, true, streamInfo)),
+			rating: 2,
+			want: file(
+				flacMeta(flacStreamInfo, false, streamInfo),
+				flacMeta(flacVorbisComment, true, []byte("\x08\x00\x00\x00dicesong\x01\x00\x00\x00\x09\x00\x00\x00RATING=40")),
+			),
+		},
+		{
+			name: "zero clears the rating",
+			file: file(
+				flacMeta(flacStreamInfo, false, streamInfo),
+				flacMeta(flacVorbisComment, true, comment("RATING=80", "TITLE=Song")),
+			),
+			rating: 0,
+			want: file(
+				flacMeta(flacStreamInfo, false, streamInfo),
+				flacMeta(flacVorbisComment, true, comment("TITLE=Song")),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteRating(t, "song.flac", tt.file, tt.rating)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got %d bytes, want %d; first difference at %d", len(got), len(tt.want), firstDiff(got, tt.want))
+			}
+		})
+	}
+
+	// A block running past the file is left alone.
+	broken := append([]byte("fLaC"), flacMeta(flacStreamInfo, true, streamInfo)[:20]...)
+	got, err := rewriteRating(t, "song.flac", broken, 3)
+	if err == nil || !bytes.Equal(got, broken) {
+		t.Errorf("truncated file: err = %v, changed = %v", err, !bytes.Equal(got, broken))
+	}
+}
@@ -0,0 +1,119 @@
+package tags
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+const (
+	id3HeaderSize = 10
+	id3Padding    = 1024
+	popmEmail     = "dicesong"
+)
+
+var errUnsupportedID3 = errors.New("unsupported ID3v2 tag layout")
+
+// popmRatings maps 0-5 stars onto the POPM byte scale used by most players.
+var popmRatings = [...]byte{0, 1, 64, 128, 196, 255}
+
+func writeID3Rating(path string, rating int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	version := byte(3)
+	var frames []byte
+	audio := data
+
+	if len(data) >= id3HeaderSize && string(data[:3]) == "ID3" {
+		version = data[3]
+		flags := data[5]
+		if version != 3 && version != 4 {
+			return errUnsupportedID3
+		}
+		if flags&0xc0 != 0 {
+			return errUnsupportedID3
+		}
+
+		size := int(syncsafe(data[6:10]))
+		end := id3HeaderSize + size
+		if flags&0x10 != 0 {
+			end += id3HeaderSize
+		}
+		if end > len(data) {
+			return errUnsupportedID3
+		}
+
+		frames, err = stripFrames(data[id3HeaderSize:id3HeaderSize+size], version, "POPM")
+		if err != nil {
+			return err
+		}
+		audio = data[end:]
+	}
+
+	if rating > 0 {
+		body := append([]byte(popmEmail), 0, popmRatings[min(rating, len(popmRatings)-1)])
+		frames = append(frames, id3Frame(version, "POPM", body)...)
+	}
+
+	tagSize := len(frames) + id3Padding
+	out := make([]byte, 0, id3HeaderSize+tagSize+len(audio))
+	out = append(out, 'I', 'D', '3', version, 0, 0)
+	out = append(out, encodeSyncsafe(uint32(tagSize))...)
+	out = append(out, frames...)
+	out = append(out, make([]byte, id3Padding)...)
+	out = append(out, audio...)
+	return writeFile(path, out)
+}
+
+func stripFrames(body []byte, version byte, id string) ([]byte, error) {
+	var kept []byte
+	pos := 0
+	for pos+id3HeaderSize <= len(body) {
+		if body[pos] == 0 {
+			break
+		}
+		frameID := string(body[pos : pos+4])
+		size := int(frameSize(body[pos+4:pos+8], version))
+		end := pos + id3HeaderSize + size
+		if end > len(body) {
+			return nil, errUnsupportedID3
+		}
+		// Every POPM frame is replaced so other players don't keep
+		// reporting a stale rating next to ours.
+		if frameID != id {
+			kept = append(kept, body[pos:end]...)
+		}
+		pos = end
+	}
+	return kept, nil
+}
+
+func id3Frame(version byte, id string, body []byte) []byte {
+	frame := make([]byte, 0, id3HeaderSize+len(body))
+	frame = append(frame, id...)
+	if version == 4 {
+		frame = append(frame, encodeSyncsafe(uint32(len(body)))...)
+	} else {
+		frame = binary.BigEndian.AppendUint32(frame, uint32(len(body)))
+	}
+	frame = append(frame, 0, 0)
+	return append(frame, body...)
+}
+
+func frameSize(b []byte, version byte) uint32 {
+	if version == 4 {
+		return syncsafe(b)
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0])<<21 | uint32(b[1])<<14 | uint32(b[2])<<7 | uint32(b[3])
+}
+
+func encodeSyncsafe(n uint32) []byte {
+	return []byte{byte(n>>21) & 0x7f, byte(n>>14) & 0x7f, byte(n>>7) & 0x7f, byte(n) & 0x7f}
+}
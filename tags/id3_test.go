@@ -0,0 +1,135 @@
+package tags
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rewriteRating runs WriteRating on a copy of data named name and returns
+// the file afterwards.
+func rewriteRating(t *testing.T, name string, data []byte, rating int) ([]byte, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteRating(path, rating)
+	out, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	return out, err
+}
+
+func firstDiff(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func rawFrame(id string, size []byte, body string) []byte {
+	return append(append(append([]byte(id), size...), 0, 0), body...)
+}
+
+func id3Tag(version, flags byte, body ...[]byte) []byte {
+	joined := bytes.Join(body, nil)
+	out := []byte{'I', 'D', '3', version, 0, flags}
+	return append(append(out, encodeSyncsafe(uint32(len(joined)))...), joined...)
+}
+
+func TestWriteID3Rating(t *testing.T) {
+	audio := []byte("\xff\xfbaudio")
+	padding := make([]byte, id3Padding)
+	title := rawFrame("TIT2", []byte{0, 0, 0, 5}, "\x00Song")
+	// 200 bytes is syncsafe 0x00 0x00 0x01 0x48 in v2.4 but 0xc8 in v2.3.
+	long := "\x00" + strings.Repeat("x", 199)
+	longV3 := rawFrame("TIT2", []byte{0, 0, 0, 0xc8}, long)
+	longV4 := rawFrame("TIT2", []byte{0, 0, 1, 0x48}, long)
+	popm := func(rating byte) []byte {
+		return rawFrame("POPM", []byte{0, 0, 0, 10}, "dicesong\x00"+string([]byte{rating}))
+	}
+	otherPOPM := rawFrame("POPM", []byte{0, 0, 0, 11}, "a@b.c\x00\x40\x00\x00\x00\x07")
+	footer := []byte("3DI\x04\x00\x10\x00\x00\x00\x00")
+
+	tests := []struct {
+		name   string
+		file   []byte
+		rating int
+		want   []byte
+	}{
+		{
+			name:   "no tag",
+			file:   audio,
+			rating: 3,
+			want:   append(id3Tag(3, 0, popm(128), padding), audio...),
+		},
+		{
+			name:   "v2.3 with other POPM frames",
+			file:   append(id3Tag(3, 0, otherPOPM, longV3, popm(1), make([]byte, 30)), audio...),
+			rating: 5,
+			want:   append(id3Tag(3, 0, longV3, popm(255), padding), audio...),
+		},
+		{
+			name:   "v2.4 syncsafe frame sizes",
+			file:   append(id3Tag(4, 0, longV4, otherPOPM), audio...),
+			rating: 2,
+			want:   append(id3Tag(4, 0, longV4, rawFrame("POPM", []byte{0, 0, 0, 10}, "dicesong\x00\x40"), padding), audio...),
+		},
+		{
+			name:   "v2.4 footer is dropped",
+			file:   append(append(id3Tag(4, 0x10, title), footer...), audio...),
+			rating: 1,
+			want:   append(id3Tag(4, 0, title, popm(1), padding), audio...),
+		},
+		{
+			name:   "zero clears the rating",
+			file:   append(id3Tag(3, 0, title, otherPOPM), audio...),
+			rating: 0,
+			want:   append(id3Tag(3, 0, title, padding), audio...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteRating(t, "song.mp3", tt.file, tt.rating)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got %d bytes, want %d; first difference at %d", len(got), len(tt.want), firstDiff(got, tt.want))
+			}
+		})
+	}
+}
+
+func TestWriteID3RatingUnsupported(t *testing.T) {
+	audio := []byte("\xff\xfbaudio")
+	title := rawFrame("TIT2", []byte{0, 0, 0, 5}, "\x00Song")
+
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{"v2.2", append(id3Tag(2, 0, []byte("TT2\x00\x00\x05\x00Song")), audio...)},
+		{"unsynchronised", append(id3Tag(3, 0x80, title), audio...)},
+		{"extended header", append(id3Tag(3, 0x40, title), audio...)},
+		{"frame past the tag", append(id3Tag(3, 0, rawFrame("TIT2", []byte{0, 0, 1, 0}, "\x00Song")), audio...)},
+		{"tag past the file", id3Tag(3, 0, title)[:12]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteRating(t, "song.mp3", tt.file, 4)
+			if !errors.Is(err, errUnsupportedID3) {
+				t.Errorf("err = %v, want %v", err, errUnsupportedID3)
+			}
+			if !bytes.Equal(got, tt.file) {
+				t.Errorf("file was changed to %q", got)
+			}
+		})
+	}
+}
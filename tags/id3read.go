@@ -0,0 +1,241 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+func readID3(r io.ReadSeeker) (Tags, error) {
+	var t Tags
+	found, err := readID3Frames(r, t.id3Frame)
+	if !found {
+		return readID3v1(r, t)
+	}
+	if err != nil {
+		return t, err
+	}
+	if t.Raw == nil {
+		return readID3v1(r, t)
+	}
+	return t, nil
+}
+
+// readID3Frames calls frame for every ID3v2 frame and reports whether
+// there was an ID3v2 tag at all.
+func readID3Frames(r io.Reader, frame func(id string, data []byte)) (bool, error) {
+	header := make([]byte, id3HeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:3]) != "ID3" {
+		return false, nil
+	}
+
+	version := header[3]
+	flags := header[5]
+	body := make([]byte, syncsafe(header[6:10]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return true, err
+	}
+	if version < 4 && flags&0x80 != 0 {
+		body = removeUnsync(body)
+	}
+
+	pos := 0
+	if flags&0x40 != 0 && len(body) >= 4 {
+		if version == 3 {
+			pos = 4 + int(binary.BigEndian.Uint32(body))
+		} else {
+			pos = int(syncsafe(body[:4]))
+		}
+	}
+
+	idLen, headerLen := 4, id3HeaderSize
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	for pos+headerLen <= len(body) && body[pos] != 0 {
+		id := string(body[pos : pos+idLen])
+		var size int
+		var frameFlags uint16
+		switch version {
+		case 2:
+			size = int(body[pos+3])<<16 | int(body[pos+4])<<8 | int(body[pos+5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(body[pos+4:]))
+			frameFlags = binary.BigEndian.Uint16(body[pos+8:])
+		default:
+			size = int(syncsafe(body[pos+4 : pos+8]))
+			frameFlags = binary.BigEndian.Uint16(body[pos+8:])
+		}
+
+		start := pos + headerLen
+		end := start + size
+		if size < 0 || end > len(body) {
+			break
+		}
+		pos = end
+
+		data := body[start:end]
+		switch version {
+		case 3:
+			if frameFlags&0x00c0 != 0 {
+				continue
+			}
+		case 4:
+			if frameFlags&0x000c != 0 {
+				continue
+			}
+			if frameFlags&0x0002 != 0 {
+				data = removeUnsync(data)
+			}
+			if frameFlags&0x0001 != 0 && len(data) >= 4 {
+				data = data[4:]
+			}
+		}
+		frame(id, data)
+	}
+	return true, nil
+}
+
+func (t *Tags) id3Frame(id string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	switch id {
+	case "TXXX", "TXX":
+		desc, value := splitText(data[0], data[1:])
+		t.set(strings.ToUpper(decodeText(data[0], desc)), decodeText(data[0], value))
+	case "COMM", "COM":
+		if len(data) < 4 {
+			return
+		}
+		_, text := splitText(data[0], data[4:])
+		t.set(id, decodeText(data[0], text))
+	case "POPM", "POP":
+		_, rest, ok := bytes.Cut(data, []byte{0})
+		if ok && len(rest) > 0 {
+			t.set(id, strconv.Itoa(int(rest[0])))
+		}
+	default:
+		if id[0] == 'T' {
+			text := decodeText(data[0], data[1:])
+			t.set(id, strings.ReplaceAll(strings.TrimRight(text, "\x00"), "\x00", "; "))
+		}
+	}
+}
+
+func readID3v1(r io.ReadSeeker, t Tags) (Tags, error) {
+	if _, err := r.Seek(-128, io.SeekEnd); err != nil {
+		return t, nil
+	}
+	tag := make([]byte, 128)
+	if _, err := io.ReadFull(r, tag); err != nil || string(tag[:3]) != "TAG" {
+		return t, nil
+	}
+
+	field := func(b []byte) string {
+		b, _, _ = bytes.Cut(b, []byte{0})
+		return strings.TrimSpace(decodeLatin1(b))
+	}
+	t.set("TITLE", field(tag[3:33]))
+	t.set("ARTIST", field(tag[33:63]))
+	t.set("ALBUM", field(tag[63:93]))
+	t.set("YEAR", field(tag[93:97]))
+	t.set("COMMENT", field(tag[97:127]))
+	if tag[125] == 0 && tag[126] != 0 {
+		t.set("TRACKNUMBER", strconv.Itoa(int(tag[126])))
+	}
+	if int(tag[127]) < len(id3v1Genres) {
+		t.set("GENRE", id3v1Genres[tag[127]])
+	}
+	return t, nil
+}
+
+func splitText(encoding byte, b []byte) ([]byte, []byte) {
+	if encoding == 1 || encoding == 2 {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:]
+			}
+		}
+		return b, nil
+	}
+	before, after, _ := bytes.Cut(b, []byte{0})
+	return before, after
+}
+
+func decodeText(encoding byte, b []byte) string {
+	switch encoding {
+	case 0:
+		return decodeLatin1(b)
+	case 1, 2:
+		bigEndian := encoding == 2
+		if len(b) >= 2 {
+			switch {
+			case b[0] == 0xff && b[1] == 0xfe:
+				bigEndian, b = false, b[2:]
+			case b[0] == 0xfe && b[1] == 0xff:
+				bigEndian, b = true, b[2:]
+			}
+		}
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			if bigEndian {
+				units[i] = binary.BigEndian.Uint16(b[2*i:])
+			} else {
+				units[i] = binary.LittleEndian.Uint16(b[2*i:])
+			}
+		}
+		return string(utf16.Decode(units))
+	default:
+		return string(b)
+	}
+}
+
+func decodeLatin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func removeUnsync(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		out = append(out, b[i])
+		if b[i] == 0xff && i+1 < len(b) && b[i+1] == 0 {
+			i++
+		}
+	}
+	return out
+}
+
+var id3v1Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge",
+	"Hip-Hop", "Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B",
+	"Rap", "Reggae", "Rock", "Techno", "Industrial", "Alternative", "Ska",
+	"Death Metal", "Pranks", "Soundtrack", "Euro-Techno", "Ambient",
+	"Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance", "Classical",
+	"Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative",
+	"Instrumental Pop", "Instrumental Rock", "Ethnic", "Gothic", "Darkwave",
+	"Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream",
+	"Southern Rock", "Comedy", "Cult", "Gangsta", "Top 40", "Christian Rap",
+	"Pop/Funk", "Jungle", "Native American", "Cabaret", "New Wave",
+	"Psychadelic", "Rave", "Showtunes", "Trailer", "Lo-Fi", "Tribal",
+	"Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll",
+	"Hard Rock", "Folk", "Folk-Rock", "National Folk", "Swing", "Fast Fusion",
+	"Bebob", "Latin", "Revival", "Celtic", "Bluegrass", "Avantgarde",
+	"Gothic Rock", "Progressive Rock", "Psychedelic Rock", "Symphonic Rock",
+	"Slow Rock", "Big Band", "Chorus", "Easy Listening", "Acoustic", "Humour",
+	"Speech", "Chanson", "Opera", "Chamber Music", "Sonata", "Symphony",
+	"Booty Bass", "Primus", "Porn Groove", "Satire", "Slow Jam", "Club",
+	"Tango", "Samba", "Folklore", "Ballad", "Power Ballad", "Rhythmic Soul",
+	"Freestyle", "Duet", "Punk Rock", "Drum Solo", "A capella", "Euro-House",
+	"Dance Hall",
+}
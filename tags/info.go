@@ -0,0 +1,322 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Info holds the stream properties read from an audio file's headers.
+type Info struct {
+	Codec      string        `json:"codec,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+	SampleRate int           `json:"sample_rate,omitempty"`
+	Channels   int           `json:"channels,omitempty"`
+	BitDepth   int           `json:"bit_depth,omitempty"`
+	Bitrate    int           `json:"bitrate,omitempty"` // kbit/s
+}
+
+const (
+	mp3ScanBytes = 64 * 1024
+	oggTailBytes = 64 * 1024
+)
+
+var errInvalidMP3 = errors.New("no MPEG audio frame found")
+
+func ReadInfo(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".mp3":
+		info, err = readMP3Info(f, stat.Size())
+	case ".flac":
+		info, err = readFLACInfo(f)
+	case ".ogg", ".oga":
+		info, err = readOggInfo(f, stat.Size())
+	case ".wav":
+		info, err = readWAVInfo(f)
+	default:
+		return Info{}, fmt.Errorf("stream info not supported for %s files", ext)
+	}
+	if err == nil && info.Bitrate == 0 && info.Duration > 0 {
+		info.Bitrate = int(float64(stat.Size()*8) / info.Duration.Seconds() / 1000)
+	}
+	return info, err
+}
+
+func samplesDuration(samples int64, rate int) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(samples) * time.Second / time.Duration(rate)
+}
+
+func readFLACInfo(r io.Reader) (Info, error) {
+	blocks, _, err := readFLACBlocks(r, func(kind byte) bool { return kind == flacStreamInfo })
+	if err != nil {
+		return Info{}, err
+	}
+	for _, block := range blocks {
+		if block.kind != flacStreamInfo || len(block.body) < 18 {
+			continue
+		}
+		b := block.body[10:18]
+		rate := int(b[0])<<12 | int(b[1])<<4 | int(b[2])>>4
+		samples := int64(b[3]&0x0f)<<32 | int64(binary.BigEndian.Uint32(b[4:8]))
+		return Info{
+			Codec:      "FLAC",
+			SampleRate: rate,
+			Channels:   int(b[2]>>1&0x07) + 1,
+			BitDepth:   int(b[2]&0x01)<<4 | int(b[3]>>4) + 1,
+			Duration:   samplesDuration(samples, rate),
+		}, nil
+	}
+	return Info{}, errInvalidFLAC
+}
+
+func readWAVInfo(r io.ReadSeeker) (Info, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return Info{}, errInvalidWAV
+	}
+
+	info := Info{Codec: "WAV"}
+	byteRate := 0
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return info, errInvalidWAV
+		}
+		id := string(chunk[:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:]))
+
+		switch id {
+		case "fmt ":
+			// A format chunk is 16 to 40 bytes; anything else is corrupt
+			// and not worth allocating for.
+			if size < 16 || size > 64 {
+				if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+					return info, errInvalidWAV
+				}
+				continue
+			}
+			body := make([]byte, size)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return info, errInvalidWAV
+			}
+			info.Channels = int(binary.LittleEndian.Uint16(body[2:]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			byteRate = int(binary.LittleEndian.Uint32(body[8:]))
+			info.BitDepth = int(binary.LittleEndian.Uint16(body[14:]))
+			info.Bitrate = byteRate * 8 / 1000
+			if size%2 == 1 {
+				_, _ = r.Seek(1, io.SeekCurrent)
+			}
+		case "data":
+			if byteRate > 0 {
+				info.Duration = time.Duration(size) * time.Second / time.Duration(byteRate)
+			}
+			return info, nil
+		default:
+			if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return info, errInvalidWAV
+			}
+		}
+	}
+}
+
+func readOggInfo(r io.ReadSeeker, size int64) (Info, error) {
+	packets, err := readOggPackets(r, 1)
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+	// Opus granule positions always count 48 kHz samples.
+	granuleRate := 0
+	id := packets[0]
+	switch {
+	case len(id) >= 28 && string(id[:7]) == "\x01vorbis":
+		info.Codec = "Vorbis"
+		info.Channels = int(id[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(id[12:]))
+		info.Bitrate = int(int32(binary.LittleEndian.Uint32(id[20:]))) / 1000
+		granuleRate = info.SampleRate
+	case len(id) >= 16 && string(id[:8]) == "OpusHead":
+		info.Codec = "Opus"
+		info.Channels = int(id[9])
+		info.SampleRate = int(binary.LittleEndian.Uint32(id[12:]))
+		granuleRate = 48000
+	default:
+		return Info{}, errInvalidOgg
+	}
+	info.Bitrate = max(info.Bitrate, 0)
+
+	start := max(size-oggTailBytes, 0)
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return info, nil
+	}
+	tail, _ := io.ReadAll(r)
+	if i := bytes.LastIndex(tail, []byte("OggS")); i >= 0 && i+14 <= len(tail) {
+		granule := int64(binary.LittleEndian.Uint64(tail[i+6:]))
+		if granule > 0 {
+			info.Duration = samplesDuration(granule, granuleRate)
+		}
+	}
+	return info, nil
+}
+
+var (
+	mp3Bitrates = [2][3][16]int{
+		{ // MPEG-1: layer I, II, III
+			{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+			{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		},
+		{ // MPEG-2 and 2.5
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		},
+	}
+	mp3SampleRates = [3]int{44100, 48000, 32000}
+)
+
+type mp3Frame struct {
+	mpeg1      bool
+	layer      int // 1, 2 or 3
+	bitrate    int
+	sampleRate int
+	mono       bool
+}
+
+func parseMP3Frame(b []byte) (mp3Frame, bool) {
+	if b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+		return mp3Frame{}, false
+	}
+	version := b[1] >> 3 & 0x03 // 0: 2.5, 2: 2, 3: 1
+	layer := 4 - int(b[1]>>1&0x03)
+	bitrateIndex := b[2] >> 4
+	rateIndex := b[2] >> 2 & 0x03
+	if version == 1 || layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	f := mp3Frame{mpeg1: version == 3, layer: layer, mono: b[3]>>6 == 3}
+	table := 1
+	if f.mpeg1 {
+		table = 0
+	}
+	f.bitrate = mp3Bitrates[table][layer-1][bitrateIndex]
+	f.sampleRate = mp3SampleRates[rateIndex]
+	switch version {
+	case 2:
+		f.sampleRate /= 2
+	case 0:
+		f.sampleRate /= 4
+	}
+	return f, true
+}
+
+func (f mp3Frame) samplesPerFrame() int {
+	switch {
+	case f.layer == 1:
+		return 384
+	case f.layer == 3 && !f.mpeg1:
+		return 576
+	default:
+		return 1152
+	}
+}
+
+func (f mp3Frame) sideInfoSize() int {
+	switch {
+	case f.mpeg1 && f.mono:
+		return 17
+	case f.mpeg1:
+		return 32
+	case f.mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func readMP3Info(r io.ReadSeeker, size int64) (Info, error) {
+	var audioStart int64
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err == nil && string(header[:3]) == "ID3" {
+		audioStart = 10 + int64(syncsafe(header[6:10]))
+		if header[5]&0x10 != 0 {
+			audioStart += 10
+		}
+	}
+	if _, err := r.Seek(audioStart, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+	buf := make([]byte, mp3ScanBytes)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		frame, ok := parseMP3Frame(buf[i:])
+		if !ok {
+			continue
+		}
+		info := Info{
+			Codec:      fmt.Sprintf("MP%d", frame.layer),
+			SampleRate: frame.sampleRate,
+			Channels:   2 - boolInt(frame.mono),
+		}
+		start := audioStart + int64(i)
+
+		if frames := vbrFrames(buf[i:], frame); frames > 0 {
+			info.Duration = samplesDuration(int64(frames)*int64(frame.samplesPerFrame()), frame.sampleRate)
+			if info.Duration > 0 {
+				info.Bitrate = int(float64((size-start)*8) / info.Duration.Seconds() / 1000)
+			}
+			return info, nil
+		}
+		info.Bitrate = frame.bitrate
+		info.Duration = time.Duration((size-start)*8) * time.Second / time.Duration(frame.bitrate*1000)
+		return info, nil
+	}
+	return Info{}, errInvalidMP3
+}
+
+// vbrFrames reads the frame count from a Xing/Info or VBRI header in the
+// first frame, or returns 0 for a constant bitrate stream.
+func vbrFrames(b []byte, f mp3Frame) uint32 {
+	if off := 4 + f.sideInfoSize(); off+12 <= len(b) {
+		if tag := string(b[off : off+4]); (tag == "Xing" || tag == "Info") && b[off+7]&0x01 != 0 {
+			return binary.BigEndian.Uint32(b[off+8:])
+		}
+	}
+	if len(b) >= 36+18 && string(b[36:40]) == "VBRI" {
+		return binary.BigEndian.Uint32(b[36+14:])
+	}
+	return 0
+}
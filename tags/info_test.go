@@ -0,0 +1,78 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// riffChunk encodes one RIFF chunk, padded to an even length.
+func riffChunk(id string, size uint32, body []byte) []byte {
+	out := append([]byte(id), binary.LittleEndian.AppendUint32(nil, size)...)
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func wavFile(chunks ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func wavFormat(channels, rate, bits int) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, 1)
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate*channels*bits/8))
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels*bits/8))
+	return binary.LittleEndian.AppendUint16(b, uint16(bits))
+}
+
+func TestReadWAVInfo(t *testing.T) {
+	format := wavFormat(2, 44100, 16)
+	data := riffChunk("data", 44100*4*3, nil)
+
+	tests := []struct {
+		name string
+		file []byte
+		want Info
+	}{
+		{
+			name: "plain",
+			file: wavFile(riffChunk("fmt ", 16, format), data),
+			want: Info{Codec: "WAV", Channels: 2, SampleRate: 44100, BitDepth: 16, Bitrate: 1411, Duration: 3 * time.Second},
+		},
+		{
+			name: "odd chunk before the format",
+			file: wavFile(riffChunk("junk", 3, []byte("abc")), riffChunk("fmt ", 16, format), data),
+			want: Info{Codec: "WAV", Channels: 2, SampleRate: 44100, BitDepth: 16, Bitrate: 1411, Duration: 3 * time.Second},
+		},
+		{
+			// The size is trusted only as far as seeking past the chunk.
+			name: "huge format chunk",
+			file: wavFile(riffChunk("fmt ", 0xfffffff0, format), data),
+			want: Info{Codec: "WAV"},
+		},
+		{
+			name: "short format chunk",
+			file: wavFile(riffChunk("fmt ", 8, format[:8]), data),
+			want: Info{Codec: "WAV"},
+		},
+	}
+	for _, tt := range tests {
+		got, _ := readWAVInfo(bytes.NewReader(tt.file))
+		if got != tt.want {
+			t.Errorf("%s: readWAVInfo = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := readWAVInfo(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI "))); err == nil {
+		t.Error("readWAVInfo of a non-WAV RIFF file succeeded")
+	}
+}
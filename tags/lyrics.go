@@ -0,0 +1,108 @@
+package tags
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SYLT timestamps in milliseconds; the other format counts MPEG frames.
+const syltMilliseconds = 2
+
+var ErrNoLyrics = errors.New("no embedded lyrics")
+
+// lyricsKeys are the Vorbis comments holding lyrics, best first.
+var lyricsKeys = []string{"LYRICS", "UNSYNCEDLYRICS", "UNSYNCED LYRICS"}
+
+// SyncedLine is one line of an ID3 SYLT frame.
+type SyncedLine struct {
+	Time time.Duration
+	Text string
+}
+
+// Lyrics are the lyrics embedded in a file: plain text, which is often
+// in LRC format anyway, and lines with timestamps from a SYLT frame.
+type Lyrics struct {
+	Text   string
+	Synced []SyncedLine
+}
+
+func ReadLyrics(path string) (Lyrics, error) {
+	var l Lyrics
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		f, err := os.Open(path)
+		if err != nil {
+			return l, err
+		}
+		defer f.Close()
+		_, err = readID3Frames(f, func(id string, data []byte) {
+			switch id {
+			case "USLT", "ULT":
+				if l.Text == "" {
+					l.Text = parseUSLT(data)
+				}
+			case "SYLT", "SLT":
+				if l.Synced == nil {
+					l.Synced = parseSYLT(data)
+				}
+			}
+		})
+		if err != nil {
+			return l, err
+		}
+	default:
+		t, err := Read(path)
+		if err != nil {
+			return l, err
+		}
+		for _, key := range lyricsKeys {
+			if text := t.Raw[key]; text != "" {
+				l.Text = text
+				break
+			}
+		}
+	}
+	if strings.TrimSpace(l.Text) == "" && len(l.Synced) == 0 {
+		return l, ErrNoLyrics
+	}
+	return l, nil
+}
+
+// parseUSLT reads the text of a USLT frame, which follows its encoding,
+// language and description.
+func parseUSLT(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	_, text := splitText(data[0], data[4:])
+	return strings.TrimRight(decodeText(data[0], text), "\x00")
+}
+
+// parseSYLT reads the lines of a SYLT frame timed in milliseconds. Each
+// line is a terminated string followed by its timestamp.
+func parseSYLT(data []byte) []SyncedLine {
+	if len(data) < 6 || data[4] != syltMilliseconds {
+		return nil
+	}
+	encoding := data[0]
+	_, rest := splitText(encoding, data[6:])
+
+	var lines []SyncedLine
+	for len(rest) > 0 {
+		text, after := splitText(encoding, rest)
+		if len(after) < 4 {
+			break
+		}
+		ms := binary.BigEndian.Uint32(after)
+		rest = after[4:]
+		lines = append(lines, SyncedLine{
+			Time: time.Duration(ms) * time.Millisecond,
+			Text: strings.TrimPrefix(decodeText(encoding, text), "\n"),
+		})
+	}
+	return lines
+}
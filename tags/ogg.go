@@ -0,0 +1,302 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+const maxOggHeaderPages = 64
+
+var (
+	errInvalidOgg     = errors.New("invalid Ogg Vorbis stream")
+	errUnsupportedOgg = errors.New("unsupported Ogg stream layout")
+)
+
+// readOggPackets reassembles the first n packets of the first logical stream.
+func readOggPackets(r io.Reader, n int) ([][]byte, error) {
+	var packets [][]byte
+	var current []byte
+	header := make([]byte, 27)
+
+	for page := 0; page < maxOggHeaderPages && len(packets) < n; page++ {
+		if _, err := io.ReadFull(r, header); err != nil || string(header[:4]) != "OggS" {
+			return nil, errInvalidOgg
+		}
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(r, segments); err != nil {
+			return nil, errInvalidOgg
+		}
+		total := 0
+		for _, s := range segments {
+			total += int(s)
+		}
+		data := make([]byte, total)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errInvalidOgg
+		}
+
+		pos := 0
+		for _, s := range segments {
+			current = append(current, data[pos:pos+int(s)]...)
+			pos += int(s)
+			if s < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == n {
+					break
+				}
+			}
+		}
+	}
+	if len(packets) < n {
+		return nil, errInvalidOgg
+	}
+	return packets, nil
+}
+
+func readOgg(r io.Reader) (Tags, error) {
+	var t Tags
+	comments, err := readOggComments(r)
+	if err != nil {
+		return t, err
+	}
+	t.setComments(comments)
+	return t, nil
+}
+
+// readOggComments returns the comments of a Vorbis or Opus stream.
+func readOggComments(r io.Reader) ([]string, error) {
+	packets, err := readOggPackets(r, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	magic, ok := oggCommentMagic(packets[1])
+	if !ok {
+		return nil, errInvalidOgg
+	}
+	_, comments, err := parseVorbisComment(packets[1][len(magic):])
+	return comments, err
+}
+
+// oggCommentMagic returns the prefix of a Vorbis or Opus comment packet.
+func oggCommentMagic(packet []byte) (string, bool) {
+	for _, magic := range []string{"\x03vorbis", "OpusTags"} {
+		if bytes.HasPrefix(packet, []byte(magic)) {
+			return magic, true
+		}
+	}
+	return "", false
+}
+
+type oggPage struct {
+	flags    byte
+	granule  uint64
+	serial   uint32
+	sequence uint32
+	segments []byte
+	data     []byte
+}
+
+const (
+	oggContinued = 0x01
+	oggFirst     = 0x02
+)
+
+// parseOggPage parses the page at the start of b and returns its length.
+func parseOggPage(b []byte) (oggPage, int, error) {
+	if len(b) < 27 || string(b[:4]) != "OggS" || len(b) < 27+int(b[26]) {
+		return oggPage{}, 0, errInvalidOgg
+	}
+	segments := b[27 : 27+int(b[26])]
+	end := 27 + len(segments)
+	for _, s := range segments {
+		end += int(s)
+	}
+	if end > len(b) {
+		return oggPage{}, 0, errInvalidOgg
+	}
+	return oggPage{
+		flags:    b[5],
+		granule:  binary.LittleEndian.Uint64(b[6:]),
+		serial:   binary.LittleEndian.Uint32(b[14:]),
+		sequence: binary.LittleEndian.Uint32(b[18:]),
+		segments: segments,
+		data:     b[27+len(segments) : end],
+	}, end, nil
+}
+
+func (p oggPage) bytes() []byte {
+	out := append([]byte("OggS"), 0, p.flags)
+	out = binary.LittleEndian.AppendUint64(out, p.granule)
+	out = binary.LittleEndian.AppendUint32(out, p.serial)
+	out = binary.LittleEndian.AppendUint32(out, p.sequence)
+	out = binary.LittleEndian.AppendUint32(out, 0)
+	out = append(out, byte(len(p.segments)))
+	out = append(out, p.segments...)
+	out = append(out, p.data...)
+	binary.LittleEndian.PutUint32(out[22:], oggCRC(out))
+	return out
+}
+
+var oggCRCTable = func() (table [256]uint32) {
+	for i := range table {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggCRC is the unreflected CRC-32 of Ogg pages, which hash/crc32 lacks.
+func oggCRC(b []byte) uint32 {
+	var crc uint32
+	for _, c := range b {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^c]
+	}
+	return crc
+}
+
+// oggHeaderPages lays the header packets out on pages, with the
+// identification header alone on the first one as Vorbis and Opus require.
+func oggHeaderPages(serial uint32, packets [][]byte) []oggPage {
+	var pages []oggPage
+	page := oggPage{flags: oggFirst, serial: serial}
+	flush := func(continued bool) {
+		page.sequence = uint32(len(pages))
+		pages = append(pages, page)
+		page = oggPage{serial: serial}
+		if continued {
+			page.flags = oggContinued
+		}
+	}
+
+	for i, packet := range packets {
+		for pos := 0; ; {
+			if len(page.segments) == 255 {
+				flush(pos > 0)
+			}
+			n := min(len(packet)-pos, 255)
+			page.segments = append(page.segments, byte(n))
+			page.data = append(page.data, packet[pos:pos+n]...)
+			pos += n
+			if n < 255 {
+				break
+			}
+		}
+		if i == 0 {
+			flush(false)
+		}
+	}
+	if len(page.segments) > 0 {
+		flush(false)
+	}
+	return pages
+}
+
+func writeOggRating(path string, rating int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Collect the header packets: identification and comments, plus the
+	// setup header for Vorbis.
+	var (
+		page    oggPage
+		packets [][]byte
+		current []byte
+		serial  uint32
+		pos     int
+		want    int
+	)
+	for pages := 0; want == 0 || len(packets) < want; pages++ {
+		if pages == maxOggHeaderPages {
+			return errInvalidOgg
+		}
+		var n int
+		if page, n, err = parseOggPage(data[pos:]); err != nil {
+			return err
+		}
+		pos += n
+		if pages == 0 {
+			serial = page.serial
+		} else if page.serial != serial {
+			return errUnsupportedOgg
+		}
+
+		offset := 0
+		for _, s := range page.segments {
+			current = append(current, page.data[offset:offset+int(s)]...)
+			offset += int(s)
+			if s < 255 {
+				packets = append(packets, current)
+				current = nil
+			}
+		}
+
+		if want == 0 && len(packets) > 0 {
+			switch {
+			case bytes.HasPrefix(packets[0], []byte("\x01vorbis")):
+				want = 3
+			case bytes.HasPrefix(packets[0], []byte("OpusHead")):
+				want = 2
+			default:
+				return errInvalidOgg
+			}
+		}
+	}
+	// Audio must start on a fresh page, so the pages after the headers
+	// can be kept as they are.
+	if len(packets) != want || len(current) > 0 {
+		return errUnsupportedOgg
+	}
+
+	magic, ok := oggCommentMagic(packets[1])
+	if !ok {
+		return errInvalidOgg
+	}
+	vendor, comments, err := parseVorbisComment(packets[1][len(magic):])
+	if err != nil {
+		return err
+	}
+	// Whatever follows the comments, such as the Vorbis framing bit, is kept.
+	rest := packets[1][len(magic)+len(buildVorbisComment(vendor, comments)):]
+	packet := append([]byte(magic), buildVorbisComment(vendor, setRating(comments, rating))...)
+	packets[1] = append(packet, rest...)
+
+	headers := oggHeaderPages(serial, packets)
+	var out []byte
+	for _, p := range headers {
+		out = append(out, p.bytes()...)
+	}
+
+	// The following pages of the stream are renumbered when the comments
+	// now take up a different number of pages.
+	shift := uint32(len(headers)) - 1 - page.sequence
+	for tail := data[pos:]; len(tail) > 0; {
+		p, n, err := parseOggPage(tail)
+		if err != nil || shift == 0 {
+			// Trailing bytes that aren't pages are kept as they are.
+			out = append(out, tail...)
+			break
+		}
+		if p.serial == serial {
+			p.sequence += shift
+			out = append(out, p.bytes()...)
+		} else {
+			out = append(out, tail[:n]...)
+		}
+		tail = tail[n:]
+	}
+	return writeFile(path, out)
+}
@@ -0,0 +1,166 @@
+package tags
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOggCRC(t *testing.T) {
+	if got := oggCRC([]byte("123456789")); got != 0x89a1897f {
+		t.Errorf("oggCRC = %#x, want 0x89a1897f", got)
+	}
+}
+
+// oggTestPage lays whole packets, each shorter than 255 bytes, on a page.
+func oggTestPage(flags byte, granule uint64, sequence uint32, packets ...[]byte) oggPage {
+	page := oggPage{flags: flags, granule: granule, serial: 0x1234, sequence: sequence}
+	for _, packet := range packets {
+		page.segments = append(page.segments, byte(len(packet)))
+		page.data = append(page.data, packet...)
+	}
+	return page
+}
+
+func oggTestFile(pages ...oggPage) []byte {
+	var out []byte
+	for _, p := range pages {
+		out = append(out, p.bytes()...)
+	}
+	return out
+}
+
+func TestWriteOggRatingOpus(t *testing.T) {
+	head := []byte("OpusHead\x01\x02\x38\x01\x80\xbb\x00\x00\x00\x00\x00")
+	tags := func(comments ...string) []byte {
+		// Opus keeps binary data after the comments when its low bit is set.
+		return append(append([]byte("OpusTags"), buildVorbisComment("libopus", comments)...), 0x01, 0xaa)
+	}
+	audio := []oggPage{
+		oggTestPage(0, 960, 2, []byte("audio 1")),
+		oggTestPage(0x04, 1920, 3, []byte("audio 2")),
+	}
+	file := func(comments ...string) []byte {
+		pages := []oggPage{oggTestPage(oggFirst, 0, 0, head), oggTestPage(0, 0, 1, tags(comments...))}
+		return oggTestFile(append(pages, audio...)...)
+	}
+
+	got, err := rewriteRating(t, "song.ogg", file("TITLE=Song", "Rating=20"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := file("TITLE=Song", "RATING=80"); !bytes.Equal(got, want) {
+		t.Errorf("got %d bytes, want %d; first difference at %d", len(got), len(want), firstDiff(got, want))
+	}
+	comments, err := readOggComments(bytes.NewReader(got))
+	if err != nil || strings.Join(comments, ",") != "TITLE=Song,RATING=80" {
+		t.Errorf("readOggComments = %q, %v", comments, err)
+	}
+}
+
+func TestWriteOggRatingVorbis(t *testing.T) {
+	ident := []byte("\x01vorbis\x00\x00\x00\x00\x02\x44\xac\x00\x00")
+	setup := []byte("\x05vorbis setup")
+	lyrics := "LYRICS=" + strings.Repeat("la ", 300)
+	comment := func(comments ...string) []byte {
+		return append(append([]byte("\x03vorbis"), buildVorbisComment("Xiph", comments)...), 0x01)
+	}
+
+	// The comment packet spans two pages and the setup header has a page
+	// of its own, so the rewrite saves a page and renumbers the audio.
+	packet := comment(lyrics)
+	var lacing []byte
+	for range len(packet) / 255 {
+		lacing = append(lacing, 255)
+	}
+	file := oggTestFile(
+		oggTestPage(oggFirst, 0, 0, ident),
+		oggPage{serial: 0x1234, sequence: 1, segments: lacing[:3], data: packet[:3*255]},
+		oggPage{flags: oggContinued, serial: 0x1234, sequence: 2, segments: append(lacing[3:], byte(len(packet)%255)), data: packet[3*255:]},
+		oggTestPage(0, 0, 3, setup),
+		oggTestPage(0, 1024, 4, []byte("audio 1")),
+		oggTestPage(0x04, 2048, 5, []byte("audio 2")),
+	)
+
+	got, err := rewriteRating(t, "song.ogg", file, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet = comment(lyrics, "RATING=60")
+	var segments []byte
+	for range len(packet) / 255 {
+		segments = append(segments, 255)
+	}
+	segments = append(segments, byte(len(packet)%255), byte(len(setup)))
+	want := oggTestFile(
+		oggTestPage(oggFirst, 0, 0, ident),
+		oggPage{serial: 0x1234, sequence: 1, segments: segments, data: append(packet, setup...)},
+		oggTestPage(0, 1024, 2, []byte("audio 1")),
+		oggTestPage(0x04, 2048, 3, []byte("audio 2")),
+	)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %d bytes, want %d; first difference at %d", len(got), len(want), firstDiff(got, want))
+	}
+}
+
+func TestOggHeaderPages(t *testing.T) {
+	// 300 lacing values don't fit one page, so the packet continues on
+	// a second one.
+	big := bytes.Repeat([]byte{'x'}, 299*255+10)
+	pages := oggHeaderPages(7, [][]byte{[]byte("ident"), big})
+	if len(pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(pages))
+	}
+	for i, want := range []struct {
+		flags    byte
+		segments int
+	}{{oggFirst, 1}, {0, 255}, {oggContinued, 45}} {
+		p := pages[i]
+		if p.flags != want.flags || len(p.segments) != want.segments || p.sequence != uint32(i) || p.serial != 7 {
+			t.Errorf("page %d: flags %d, %d segments, sequence %d, serial %d", i, p.flags, len(p.segments), p.sequence, p.serial)
+		}
+	}
+	packets, err := readOggPackets(bytes.NewReader(oggTestFile(pages...)), 2)
+	if err != nil || !bytes.Equal(packets[1], big) {
+		t.Errorf("readOggPackets = %d packets, %v", len(packets), err)
+	}
+}
+
+func TestWriteOggRatingUnsupported(t *testing.T) {
+	head := []byte("OpusHead\x01\x02\x38\x01\x80\xbb\x00\x00\x00\x00\x00")
+	tags := append([]byte("OpusTags"), buildVorbisComment("libopus", nil)...)
+
+	tests := []struct {
+		name string
+		file []byte
+		err  error
+	}{
+		{
+			name: "audio on the comment page",
+			file: oggTestFile(oggTestPage(oggFirst, 0, 0, head), oggTestPage(0, 960, 1, tags, []byte("audio"))),
+			err:  errUnsupportedOgg,
+		},
+		{
+			name: "not Vorbis or Opus",
+			file: oggTestFile(oggTestPage(oggFirst, 0, 0, []byte("\x80theora")), oggTestPage(0, 0, 1, tags)),
+			err:  errInvalidOgg,
+		},
+		{
+			name: "truncated",
+			file: oggTestFile(oggTestPage(oggFirst, 0, 0, head), oggTestPage(0, 0, 1, tags))[:60],
+			err:  errInvalidOgg,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteRating(t, "song.ogg", tt.file, 4)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+			if !bytes.Equal(got, tt.file) {
+				t.Error("file was changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	flacPicture = 6
+	frontCover  = 3
+)
+
+var ErrNoPicture = errors.New("no embedded picture")
+
+// pictureKeys are the Vorbis comments holding pictures. They are read by
+// ReadPicture and left out of Raw, where they would only take up space.
+var pictureKeys = []string{"METADATA_BLOCK_PICTURE", "COVERART"}
+
+type picture struct {
+	kind uint32
+	data []byte
+}
+
+// ReadPicture returns the image data of the front cover embedded in path,
+// or of the first picture when none is marked as the front cover.
+func ReadPicture(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pictures []picture
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".mp3":
+		_, err = readID3Frames(f, func(id string, data []byte) {
+			if p, ok := parseID3Picture(id, data); ok {
+				pictures = append(pictures, p)
+			}
+		})
+	case ".flac":
+		var blocks []flacBlock
+		blocks, _, err = readFLACBlocks(f, func(kind byte) bool { return kind == flacPicture })
+		for _, block := range blocks {
+			if p, ok := parseFLACPicture(block.body); ok {
+				pictures = append(pictures, p)
+			}
+		}
+	case ".ogg", ".oga":
+		pictures, err = readOggPictures(f)
+	default:
+		return nil, fmt.Errorf("pictures not supported for %s files", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range pictures {
+		if p.kind == frontCover {
+			return p.data, nil
+		}
+	}
+	if len(pictures) > 0 {
+		return pictures[0].data, nil
+	}
+	return nil, ErrNoPicture
+}
+
+// parseID3Picture reads an APIC frame, or a PIC frame in ID3v2.2 which has
+// a three letter format instead of a MIME type.
+func parseID3Picture(id string, data []byte) (picture, bool) {
+	if len(data) < 2 {
+		return picture{}, false
+	}
+	encoding, rest := data[0], data[1:]
+	switch id {
+	case "APIC":
+		_, after, ok := bytes.Cut(rest, []byte{0})
+		if !ok {
+			return picture{}, false
+		}
+		rest = after
+	case "PIC":
+		if len(rest) < 3 {
+			return picture{}, false
+		}
+		rest = rest[3:]
+	default:
+		return picture{}, false
+	}
+	if len(rest) < 1 {
+		return picture{}, false
+	}
+	kind := uint32(rest[0])
+	_, image := splitText(encoding, rest[1:])
+	if len(image) == 0 {
+		return picture{}, false
+	}
+	return picture{kind: kind, data: image}, true
+}
+
+// parseFLACPicture reads a FLAC PICTURE block, which Vorbis comments also
+// carry base64 encoded.
+func parseFLACPicture(b []byte) (picture, bool) {
+	next := func(n int) ([]byte, bool) {
+		if n < 0 || len(b) < n {
+			return nil, false
+		}
+		out := b[:n]
+		b = b[n:]
+		return out, true
+	}
+	field := func() (uint32, bool) {
+		out, ok := next(4)
+		if !ok {
+			return 0, false
+		}
+		return binary.BigEndian.Uint32(out), true
+	}
+
+	kind, ok := field()
+	if !ok {
+		return picture{}, false
+	}
+	for range 2 { // MIME type and description
+		n, ok := field()
+		if !ok {
+			return picture{}, false
+		}
+		if _, ok := next(int(n)); !ok {
+			return picture{}, false
+		}
+	}
+	if _, ok := next(16); !ok { // width, height, depth and colors
+		return picture{}, false
+	}
+	n, ok := field()
+	if !ok {
+		return picture{}, false
+	}
+	data, ok := next(int(n))
+	if !ok || len(data) == 0 {
+		return picture{}, false
+	}
+	return picture{kind: kind, data: data}, true
+}
+
+func readOggPictures(r io.Reader) ([]picture, error) {
+	comments, err := readOggComments(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var pictures []picture
+	for _, c := range comments {
+		key, value, _ := strings.Cut(c, "=")
+		if !slices.Contains(pictureKeys, strings.ToUpper(key)) {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "METADATA_BLOCK_PICTURE":
+			if p, ok := parseFLACPicture(data); ok {
+				pictures = append(pictures, p)
+			}
+		case "COVERART":
+			pictures = append(pictures, picture{kind: frontCover, data: data})
+		}
+	}
+	return pictures, nil
+}
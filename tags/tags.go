@@ -0,0 +1,181 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Tags struct {
+	Title       string            `json:"title,omitempty"`
+	Artist      string            `json:"artist,omitempty"`
+	AlbumArtist string            `json:"album_artist,omitempty"`
+	Album       string            `json:"album,omitempty"`
+	Genre       string            `json:"genre,omitempty"`
+	Comment     string            `json:"comment,omitempty"`
+	Year        int               `json:"year,omitempty"`
+	Track       int               `json:"track,omitempty"`
+	TrackTotal  int               `json:"track_total,omitempty"`
+	Disc        int               `json:"disc,omitempty"`
+	Raw         map[string]string `json:"raw,omitempty"`
+}
+
+// ErrRatingUnsupported is returned by WriteRating for formats it cannot
+// write, such as WAV.
+var ErrRatingUnsupported = errors.New("rating tags not supported")
+
+func Read(path string) (Tags, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Tags{}, err
+	}
+	defer f.Close()
+
+	var t Tags
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".mp3":
+		t, err = readID3(f)
+	case ".flac":
+		t, err = readFLAC(f)
+	case ".ogg", ".oga":
+		t, err = readOgg(f)
+	case ".wav":
+		t, err = readWAV(f)
+	default:
+		return Tags{}, fmt.Errorf("tags not supported for %s files", ext)
+	}
+	return t, err
+}
+
+func WriteRating(path string, rating int) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".mp3":
+		return writeID3Rating(path, rating)
+	case ".flac":
+		return writeFLACRating(path, rating)
+	case ".ogg", ".oga":
+		return writeOggRating(path, rating)
+	default:
+		return fmt.Errorf("%w for %s files", ErrRatingUnsupported, ext)
+	}
+}
+
+func (t *Tags) set(key, value string) {
+	value = strings.TrimRight(strings.TrimSpace(value), "\x00")
+	if value == "" {
+		return
+	}
+	if t.Raw == nil {
+		t.Raw = map[string]string{}
+	}
+	if existing, ok := t.Raw[key]; ok && existing != value {
+		t.Raw[key] = existing + "; " + value
+	} else {
+		t.Raw[key] = value
+	}
+
+	switch strings.ToUpper(key) {
+	case "TITLE", "TIT2", "TT2", "INAM":
+		t.Title = first(t.Title, value)
+	case "ARTIST", "TPE1", "TP1", "IART":
+		t.Artist = first(t.Artist, value)
+	case "ALBUMARTIST", "ALBUM ARTIST", "TPE2", "TP2":
+		t.AlbumArtist = first(t.AlbumArtist, value)
+	case "ALBUM", "TALB", "TAL", "IPRD":
+		t.Album = first(t.Album, value)
+	case "GENRE", "TCON", "TCO", "IGNR":
+		t.Genre = first(t.Genre, cleanGenre(value))
+	case "COMMENT", "DESCRIPTION", "COMM", "COM", "ICMT":
+		t.Comment = first(t.Comment, value)
+	case "DATE", "YEAR", "TDRC", "TYER", "TYE", "ICRD":
+		if t.Year == 0 {
+			t.Year = leadingInt(value)
+		}
+	case "TRACKNUMBER", "TRCK", "TRK":
+		num, total, _ := strings.Cut(value, "/")
+		t.Track = first(t.Track, leadingInt(num))
+		t.TrackTotal = first(t.TrackTotal, leadingInt(total))
+	case "TRACKTOTAL", "TOTALTRACKS":
+		t.TrackTotal = first(t.TrackTotal, leadingInt(value))
+	case "DISCNUMBER", "TPOS", "TPA":
+		num, _, _ := strings.Cut(value, "/")
+		t.Disc = first(t.Disc, leadingInt(num))
+	}
+}
+
+func first[T comparable](current, value T) T {
+	var zero T
+	if current != zero {
+		return current
+	}
+	return value
+}
+
+func leadingInt(s string) int {
+	s = strings.TrimSpace(s)
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// cleanGenre resolves ID3v1 style references like "(17)" or "(17)Rock".
+func cleanGenre(value string) string {
+	if !strings.HasPrefix(value, "(") {
+		return value
+	}
+	ref, rest, ok := strings.Cut(value[1:], ")")
+	if !ok {
+		return value
+	}
+	if rest != "" {
+		return rest
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 0 && n < len(id3v1Genres) {
+		return id3v1Genres[n]
+	}
+	return value
+}
+
+func writeFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".dicesong-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReplayGain returns the ReplayGain values among the raw tags, such as
+// REPLAYGAIN_TRACK_GAIN, along with the R128 gains of Opus files.
+func (t Tags) ReplayGain() map[string]string {
+	gains := map[string]string{}
+	for key, value := range t.Raw {
+		upper := strings.ToUpper(key)
+		if strings.HasPrefix(upper, "REPLAYGAIN_") || strings.HasPrefix(upper, "R128_") {
+			gains[upper] = value
+		}
+	}
+	return gains
+}
@@ -0,0 +1,62 @@
+package tags
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+var errInvalidWAV = errors.New("invalid WAV file")
+
+// maxWAVList caps the LIST chunks read for tags; bigger ones are skipped
+// rather than trusting a size taken from the file.
+const maxWAVList = 1 << 20
+
+func readWAV(r io.ReadSeeker) (Tags, error) {
+	var t Tags
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return t, errInvalidWAV
+	}
+
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return t, nil
+		}
+		id := string(chunk[:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		padded := size + size%2
+
+		if id != "LIST" || size < 4 || size > maxWAVList {
+			if _, err := r.Seek(padded, io.SeekCurrent); err != nil {
+				return t, nil
+			}
+			continue
+		}
+
+		body := make([]byte, size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return t, nil
+		}
+		if size%2 == 1 {
+			_, _ = r.Seek(1, io.SeekCurrent)
+		}
+		if string(body[:4]) == "INFO" {
+			t.setInfo(body[4:])
+		}
+	}
+}
+
+func (t *Tags) setInfo(body []byte) {
+	for len(body) >= 8 {
+		id := string(body[:4])
+		size := int(binary.LittleEndian.Uint32(body[4:]))
+		if 8+size > len(body) {
+			return
+		}
+		t.set(id, strings.TrimRight(string(body[8:8+size]), "\x00"))
+		body = body[min(8+size+size%2, len(body)):]
+	}
+}
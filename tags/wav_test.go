@@ -0,0 +1,26 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadWAV(t *testing.T) {
+	info := []byte("INFO")
+	info = append(info, riffChunk("INAM", 5, []byte("Song\x00"))...)
+	info = append(info, riffChunk("IART", 7, []byte("Artist\x00"))...)
+	list := riffChunk("LIST", uint32(len(info)), info)
+	format := riffChunk("fmt ", 16, wavFormat(2, 44100, 16))
+
+	got, err := readWAV(bytes.NewReader(wavFile(format, list)))
+	if err != nil || got.Title != "Song" || got.Artist != "Artist" {
+		t.Errorf("readWAV = %+v, %v", got, err)
+	}
+
+	// A LIST chunk claiming more than maxWAVList is skipped, not read.
+	huge := riffChunk("LIST", 0xfffffff0, info)
+	got, err = readWAV(bytes.NewReader(wavFile(format, huge)))
+	if err != nil || got.Title != "" {
+		t.Errorf("readWAV with a huge LIST = %+v, %v", got, err)
+	}
+}
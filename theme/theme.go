@@ -0,0 +1,164 @@
+package theme
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+const (
+	themesDir = "themes"
+	Default   = "everblush"
+	Mono      = "mono"
+)
+
+// Theme is a palette of hex colors. A theme with NoColor set uses the
+// terminal's own colors and marks the selection with reverse video.
+type Theme struct {
+	Name    string `json:"name"`
+	NoColor bool   `json:"no_color,omitempty"`
+	Bg0     string `json:"bg0"`
+	Bg1     string `json:"bg1"`
+	Fg      string `json:"fg"`
+	Gray    string `json:"gray"`
+	Red     string `json:"red"`
+	Green   string `json:"green"`
+	Yellow  string `json:"yellow"`
+	Blue    string `json:"blue"`
+	Magenta string `json:"magenta"`
+	Cyan    string `json:"cyan"`
+}
+
+var builtin = []Theme{
+	{
+		Name: "everblush",
+		Bg0:  "#141b1e", Bg1: "#232a2d", Fg: "#dadada", Gray: "#5c6a72",
+		Red: "#e57474", Green: "#8ccf7e", Yellow: "#e5c76b",
+		Blue: "#67b0e8", Magenta: "#c47fd5", Cyan: "#6cbfbf",
+	},
+	{
+		Name: "gruvbox",
+		Bg0:  "#282828", Bg1: "#3c3836", Fg: "#ebdbb2", Gray: "#928374",
+		Red: "#fb4934", Green: "#b8bb26", Yellow: "#fabd2f",
+		Blue: "#83a598", Magenta: "#d3869b", Cyan: "#8ec07c",
+	},
+	{
+		Name: "catppuccin",
+		Bg0:  "#1e1e2e", Bg1: "#313244", Fg: "#cdd6f4", Gray: "#6c7086",
+		Red: "#f38ba8", Green: "#a6e3a1", Yellow: "#f9e2af",
+		Blue: "#89b4fa", Magenta: "#cba6f7", Cyan: "#94e2d5",
+	},
+	{
+		Name: "nord",
+		Bg0:  "#2e3440", Bg1: "#3b4252", Fg: "#d8dee9", Gray: "#4c566a",
+		Red: "#bf616a", Green: "#a3be8c", Yellow: "#ebcb8b",
+		Blue: "#81a1c1", Magenta: "#b48ead", Cyan: "#88c0d0",
+	},
+	{
+		Name: "solarized",
+		Bg0:  "#002b36", Bg1: "#073642", Fg: "#839496", Gray: "#586e75",
+		Red: "#dc322f", Green: "#859900", Yellow: "#b58900",
+		Blue: "#268bd2", Magenta: "#d33682", Cyan: "#2aa198",
+	},
+	{Name: Mono, NoColor: true},
+}
+
+func Builtin() []Theme {
+	return append([]Theme(nil), builtin...)
+}
+
+// Load returns the bundled themes followed by the user's theme files from
+// the themes directory in the config directory. A user theme replaces a
+// bundled one of the same name. Colors a file leaves out come from
+// Everblush.
+func Load() ([]Theme, error) {
+	themes := Builtin()
+	files, err := filepath.Glob(filepath.Join(Dir(), "*.json"))
+	if err != nil {
+		return themes, err
+	}
+
+	var errs []error
+	for _, file := range files {
+		t, err := loadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if i := index(themes, t.Name); i >= 0 {
+			themes[i] = t
+		} else {
+			themes = append(themes, t)
+		}
+	}
+	return themes, errors.Join(errs...)
+}
+
+func Dir() string {
+	return filepath.Join(state.ConfigDir(), themesDir)
+}
+
+func loadFile(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+	t := builtin[0]
+	t.Name = ""
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for _, c := range t.colors() {
+		if !validHex(c) {
+			return Theme{}, fmt.Errorf("%s: invalid color %q", path, c)
+		}
+	}
+	return t, nil
+}
+
+func (t Theme) colors() []string {
+	return []string{t.Bg0, t.Bg1, t.Fg, t.Gray, t.Red, t.Green, t.Yellow, t.Blue, t.Magenta, t.Cyan}
+}
+
+func validHex(c string) bool {
+	hex, ok := strings.CutPrefix(c, "#")
+	if !ok || (len(hex) != 6 && len(hex) != 3) {
+		return false
+	}
+	for _, r := range strings.ToLower(hex) {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the theme called name, or the default theme.
+func Find(themes []Theme, name string) Theme {
+	if i := index(themes, name); i >= 0 {
+		return themes[i]
+	}
+	return builtin[0]
+}
+
+// Next returns the theme after name, wrapping around.
+func Next(themes []Theme, name string) Theme {
+	return themes[(index(themes, name)+1)%len(themes)]
+}
+
+func index(themes []Theme, name string) int {
+	for i, t := range themes {
+		if strings.EqualFold(t.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/library"
+	"github.com/Gylmynnn/dicesong/tags"
+	"github.com/charmbracelet/bubbletea"
+)
+
+type infoProbedMsg struct{}
+
+// column is an optional browser column, shown once the terminal is at
+// least minWidth cells wide.
+type column struct {
+	width    int
+	minWidth int
+	value    func(path string, info tags.Info) string
+}
+
+var columns = []column{
+	{width: 7, minWidth: 50, value: func(_ string, info tags.Info) string { return formatLength(info.Duration) }},
+	{width: 6, minWidth: 70, value: formatCodec},
+	{width: 6, minWidth: 90, value: func(_ string, info tags.Info) string { return formatSampleRate(info.SampleRate) }},
+	{width: 6, minWidth: 110, value: func(_ string, info tags.Info) string { return formatDepth(info) }},
+}
+
+func (m Model) visibleColumns() []column {
+	if m.hideColumns {
+		return nil
+	}
+	var visible []column
+	for _, c := range columns {
+		if m.width >= c.minWidth {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+func columnsWidth(cols []column) int {
+	total := 0
+	for _, c := range cols {
+		total += c.width + 1
+	}
+	return total
+}
+
+func (m Model) renderColumns(path string, cols []column) string {
+	info, ok := m.library.Info(path)
+	var out strings.Builder
+	for _, c := range cols {
+		value := ""
+		if ok {
+			value = c.value(path, info)
+		}
+		fmt.Fprintf(&out, " %*s", c.width, value)
+	}
+	return out.String()
+}
+
+// probeVisible starts probing the songs on screen that have no cached
+// stream info yet, one batch at a time. Sorting by duration needs every
+// song in the folder.
+func (m *Model) probeVisible() tea.Cmd {
+	byDuration := m.sortingByDuration()
+	if m.probing || (len(m.visibleColumns()) == 0 && !byDuration) {
+		return nil
+	}
+	end := min(m.offset+max(m.height-9, 1), len(m.entries))
+	rows := m.entries[m.offset:end]
+	if byDuration {
+		rows = m.entries
+	}
+	var paths []string
+	for _, entry := range rows {
+		if _, ok := m.library.Info(entry.path); !ok && !entry.browsable() {
+			paths = append(paths, entry.path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	m.probing = true
+	return probeInfo(m.library, paths)
+}
+
+func probeInfo(ix *library.Index, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		ix.Probe(paths)
+		return infoProbedMsg{}
+	}
+}
+
+func formatLength(d time.Duration) string {
+	if d <= 0 {
+		return "-:--"
+	}
+	seconds := int(d.Round(time.Second).Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func formatCodec(path string, info tags.Info) string {
+	if info.Codec != "" {
+		return info.Codec
+	}
+	return strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+func formatSampleRate(rate int) string {
+	switch {
+	case rate <= 0:
+		return ""
+	case rate%1000 == 0:
+		return fmt.Sprintf("%dk", rate/1000)
+	default:
+		return fmt.Sprintf("%.1fk", float64(rate)/1000)
+	}
+}
+
+// formatDepth shows the bit depth of lossless files and the bitrate of
+// lossy ones.
+func formatDepth(info tags.Info) string {
+	switch {
+	case info.BitDepth > 0:
+		return fmt.Sprintf("%dbit", info.BitDepth)
+	case info.Bitrate > 0:
+		return fmt.Sprintf("%dk", info.Bitrate)
+	default:
+		return ""
+	}
+}
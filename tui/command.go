@@ -0,0 +1,435 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/Gylmynnn/dicesong/playlist"
+	"github.com/charmbracelet/bubbletea"
+)
+
+const maxHistory = 100
+
+// errUsage makes a command show its usage instead of an error.
+var errUsage = errors.New("usage")
+
+type commandLine struct {
+	open    bool
+	input   string
+	history []string
+	// recalled is the history entry shown, or len(history) while typing
+	// a new command, which is kept in draft.
+	recalled int
+	draft    string
+	// completions are cycled through on repeated Tab.
+	completions []string
+	completion  int
+}
+
+type command struct {
+	name     string
+	usage    string
+	run      func(m *Model, arg string) (tea.Cmd, error)
+	complete func(m Model, arg string) []string
+}
+
+// commands are the : commands. A command can be shortened to any prefix
+// that only it starts with, e.g. :q for :quit.
+var commands = []command{
+	{name: "seek", usage: "seek 1:30 | +10 | -10 | 50%", run: (*Model).seekCommand},
+	{name: "vol", usage: "vol 0-100 | +10 | -10", run: (*Model).volumeCommand},
+	{name: "add", usage: "add [playlist]", run: (*Model).addCommand, complete: Model.completePlaylist},
+	{name: "save", usage: "save file.m3u8", run: (*Model).saveCommand, complete: Model.completePath},
+	{name: "theme", usage: "theme name", run: (*Model).themeCommand, complete: Model.completeTheme},
+	{name: "cd", usage: "cd [folder]", run: (*Model).cdCommand, complete: Model.completeDir},
+	{name: "sleep", usage: "sleep 30m | track | 3 tracks | off [quit] [fade 1m]", run: (*Model).sleepCommand, complete: completeSleep},
+	{name: "quit", usage: "quit", run: func(*Model, string) (tea.Cmd, error) { return tea.Quit, nil }},
+}
+
+func findCommand(name string) (command, error) {
+	var found []command
+	for _, c := range commands {
+		if c.name == name {
+			return c, nil
+		}
+		if strings.HasPrefix(c.name, name) {
+			found = append(found, c)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return command{}, fmt.Errorf("unknown command %q", name)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, c := range found {
+		names[i] = c.name
+	}
+	return command{}, fmt.Errorf("%q could be %s", name, strings.Join(names, ", "))
+}
+
+func (m *Model) openCommand() {
+	m.command.open = true
+	m.command.input = ""
+	m.command.recalled = len(m.command.history)
+	m.command.completions = nil
+}
+
+func (m *Model) updateCommand(msg tea.KeyMsg) tea.Cmd {
+	action := m.Keys.Action(keymap.ScopeCommand, msg.String())
+	if action != keymap.CommandComplete {
+		m.command.completions = nil
+	}
+
+	if msg.Type == tea.KeyBackspace {
+		runes := []rune(m.command.input)
+		if len(runes) == 0 {
+			m.command.open = false
+			return nil
+		}
+		m.command.input = string(runes[:len(runes)-1])
+		return nil
+	}
+
+	switch action {
+	case keymap.Cancel:
+		m.command.open = false
+	case keymap.Select:
+		m.command.open = false
+		return m.runCommand(m.command.input)
+	case keymap.Up:
+		m.recallCommand(-1)
+	case keymap.Down:
+		m.recallCommand(1)
+	case keymap.CommandComplete:
+		m.completeCommand()
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			m.command.input += string(msg.Runes)
+		}
+	}
+	return nil
+}
+
+// recallCommand steps through the history, older for a negative delta.
+func (m *Model) recallCommand(delta int) {
+	c := &m.command
+	i := min(max(c.recalled+delta, 0), len(c.history))
+	if i == c.recalled {
+		return
+	}
+	if c.recalled == len(c.history) {
+		c.draft = c.input
+	}
+	c.recalled = i
+	if i == len(c.history) {
+		c.input = c.draft
+	} else {
+		c.input = c.history[i]
+	}
+}
+
+func (m *Model) runCommand(input string) tea.Cmd {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil
+	}
+	m.errorMsg = ""
+	if n := len(m.command.history); n == 0 || m.command.history[n-1] != input {
+		m.command.history = append(m.command.history, input)
+		if len(m.command.history) > maxHistory {
+			m.command.history = slices.Delete(m.command.history, 0, 1)
+		}
+	}
+
+	name, arg, _ := strings.Cut(input, " ")
+	c, err := findCommand(name)
+	if err != nil {
+		m.errorMsg = "Command failed: " + err.Error()
+		return nil
+	}
+	cmd, err := c.run(m, strings.TrimSpace(arg))
+	if errors.Is(err, errUsage) {
+		m.errorMsg = "Usage: :" + c.usage
+		return nil
+	}
+	if err != nil {
+		m.errorMsg = fmt.Sprintf(":%s failed: %s", c.name, err)
+		return nil
+	}
+	return cmd
+}
+
+// completeCommand completes the command name or its argument. Repeated
+// Tabs cycle through the candidates.
+func (m *Model) completeCommand() {
+	c := &m.command
+	if len(c.completions) > 0 {
+		c.completion = (c.completion + 1) % len(c.completions)
+		c.input = c.completions[c.completion]
+		return
+	}
+
+	var candidates []string
+	name, arg, hasArg := strings.Cut(c.input, " ")
+	if !hasArg {
+		for _, cmd := range commands {
+			if strings.HasPrefix(cmd.name, name) {
+				candidates = append(candidates, cmd.name+" ")
+			}
+		}
+	} else if cmd, err := findCommand(name); err == nil && cmd.complete != nil {
+		for _, completed := range cmd.complete(*m, strings.TrimLeft(arg, " ")) {
+			candidates = append(candidates, cmd.name+" "+completed)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	c.input = candidates[0]
+	c.completion = 0
+	if len(candidates) > 1 {
+		c.completions = candidates
+	}
+}
+
+func (m Model) commandStatus() string {
+	if len(m.command.completions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("  (%d/%d)", m.command.completion+1, len(m.command.completions))
+}
+
+func (m *Model) seekCommand(arg string) (tea.Cmd, error) {
+	format, ok := player.CurrentFormat()
+	if arg == "" {
+		return nil, errUsage
+	}
+	if m.playingIndex == -1 || m.loading || !ok {
+		return nil, errors.New("nothing is playing")
+	}
+	length := format.SampleRate.D(int(m.total))
+	fraction, err := parseSeek(arg, m.playingPosition(), length)
+	if err != nil {
+		return nil, err
+	}
+	return nil, m.seek(fraction)
+}
+
+// parseSeek turns a seek argument into a fraction of the song: a time
+// such as 1:30, 90 or 1m30s, a time relative to position when it starts
+// with + or -, or a percentage such as 50%.
+func parseSeek(arg string, position, length time.Duration) (float64, error) {
+	if length <= 0 {
+		return 0, errors.New("the song has no length")
+	}
+	if percent, ok := strings.CutSuffix(arg, "%"); ok {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid percentage %q", arg)
+		}
+		return p / 100, nil
+	}
+
+	sign := 0
+	if rest, ok := strings.CutPrefix(arg, "+"); ok {
+		sign, arg = 1, rest
+	} else if rest, ok := strings.CutPrefix(arg, "-"); ok {
+		sign, arg = -1, rest
+	}
+	at, err := parseClock(arg)
+	if err != nil {
+		return 0, err
+	}
+	if sign != 0 {
+		at = position + time.Duration(sign)*at
+	}
+	return float64(at) / float64(length), nil
+}
+
+// parseClock reads [[h:]m:]s or a Go duration such as 1m30s.
+func parseClock(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	parts := strings.Split(s, ":")
+	if s == "" || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	var seconds float64
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (m *Model) volumeCommand(arg string) (tea.Cmd, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, errUsage
+	}
+	if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-") {
+		n += player.Volume()
+	}
+	player.SetVolume(n)
+	return nil, nil
+}
+
+// addCommand adds the selected song, folder or group to the end of the
+// queue, or to a named playlist.
+func (m *Model) addCommand(arg string) (tea.Cmd, error) {
+	if m.cursor >= len(m.entries) {
+		return nil, errors.New("nothing is selected")
+	}
+	tracks := m.entryTracks(m.entries[m.cursor])
+	if len(tracks) == 0 {
+		return nil, errors.New("no songs to add")
+	}
+	if arg == "" {
+		m.queue = append(slices.Clone(m.queue), tracks...)
+		return nil, nil
+	}
+
+	names := m.playlists.Names()
+	i := slices.IndexFunc(names, func(name string) bool { return strings.EqualFold(name, arg) })
+	if i < 0 {
+		return nil, fmt.Errorf("no playlist named %q", arg)
+	}
+	return nil, m.playlists.Add(names[i], tracks)
+}
+
+func (m *Model) saveCommand(arg string) (tea.Cmd, error) {
+	if arg == "" {
+		return nil, errUsage
+	}
+	if len(m.queue) == 0 {
+		return nil, errors.New("the queue is empty")
+	}
+	return nil, m.saveTracks(arg, m.queue)
+}
+
+func (m *Model) themeCommand(arg string) (tea.Cmd, error) {
+	if arg == "" {
+		return nil, errUsage
+	}
+	return nil, m.setTheme(arg)
+}
+
+// cdCommand opens a folder in the files view. Relative paths start at
+// the open folder and ~ is the home directory.
+func (m *Model) cdCommand(arg string) (tea.Cmd, error) {
+	dir := m.musicRoot
+	if arg != "" {
+		dir = m.resolvePath(arg)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a folder", arg)
+	}
+
+	m.view = filesView
+	m.viewPath = nil
+	m.currentPath = dir
+	m.entries = m.readEntries()
+	m.cursor = 0
+	m.offset = 0
+	return nil, nil
+}
+
+// resolvePath expands ~ and makes path absolute from the open folder.
+func (m Model) resolvePath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	dir := m.currentPath
+	if playlist.IsPlaylist(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return filepath.Join(dir, path)
+}
+
+func (m Model) completePlaylist(arg string) []string {
+	return completeNames(m.playlists.Names(), arg)
+}
+
+func (m Model) completeTheme(arg string) []string {
+	names := make([]string, len(m.Themes))
+	for i, t := range m.Themes {
+		names[i] = t.Name
+	}
+	return completeNames(names, arg)
+}
+
+func completeSleep(_ Model, arg string) []string {
+	return completeNames([]string{"off", "track"}, arg)
+}
+
+func completeNames(names []string, prefix string) []string {
+	var out []string
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func (m Model) completeDir(arg string) []string {
+	return m.completeFiles(arg, false)
+}
+
+func (m Model) completePath(arg string) []string {
+	return m.completeFiles(arg, true)
+}
+
+// completeFiles lists the folders, and playlists when withPlaylists is
+// set, whose path starts with arg, keeping arg's folder as typed.
+func (m Model) completeFiles(arg string, withPlaylists bool) []string {
+	typed, prefix := "", arg
+	if i := strings.LastIndexAny(arg, "/"+string(filepath.Separator)); i >= 0 {
+		typed, prefix = arg[:i+1], arg[i+1:]
+	}
+	dir := m.resolvePath(typed)
+	if typed == "" {
+		dir = m.resolvePath(".")
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if file.IsDir() {
+			out = append(out, typed+name+"/")
+		} else if withPlaylists && playlist.IsPlaylist(name) {
+			out = append(out, typed+name)
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"seek", "seek"},
+		{"se", "seek"},
+		{"sa", "save"},
+		{"q", "quit"},
+		{"v", "vol"},
+		{"cd", "cd"},
+	}
+	for _, tt := range tests {
+		c, err := findCommand(tt.name)
+		if err != nil || c.name != tt.want {
+			t.Errorf("findCommand(%q) = %q, %v, want %q", tt.name, c.name, err, tt.want)
+		}
+	}
+	for _, name := range []string{"s", "x", "seeker"} {
+		if c, err := findCommand(name); err == nil {
+			t.Errorf("findCommand(%q) = %q, want an error", name, c.name)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"0", 0},
+		{"90", 90 * time.Second},
+		{"0.5", 500 * time.Millisecond},
+		{"1:30", 90 * time.Second},
+		{"01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"1m30s", 90 * time.Second},
+		{"2h", 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := parseClock(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseClock(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "a", ":", "1:", "1:2:3:4", "1:-5", "1:x"} {
+		if got, err := parseClock(in); err == nil {
+			t.Errorf("parseClock(%q) = %v, want an error", in, got)
+		}
+	}
+}
+
+func TestParseSeek(t *testing.T) {
+	const position, length = 50 * time.Second, 200 * time.Second
+	tests := []struct {
+		arg  string
+		want float64
+	}{
+		{"0", 0},
+		{"1:40", 0.5},
+		{"+10", 0.3},
+		{"-10", 0.2},
+		{"+1m", 0.55},
+		{"50%", 0.5},
+		{"12.5%", 0.125},
+	}
+	for _, tt := range tests {
+		got, err := parseSeek(tt.arg, position, length)
+		if err != nil || got != tt.want {
+			t.Errorf("parseSeek(%q) = %v, %v, want %v", tt.arg, got, err, tt.want)
+		}
+	}
+	for _, arg := range []string{"", "%", "half%", "+", "soon"} {
+		if got, err := parseSeek(arg, position, length); err == nil {
+			t.Errorf("parseSeek(%q) = %v, want an error", arg, got)
+		}
+	}
+	if _, err := parseSeek("10", 0, 0); err == nil {
+		t.Error("parseSeek without a length succeeded, want an error")
+	}
+}
+
+func TestCompleteNames(t *testing.T) {
+	names := []string{"Everblush", "everforest", "Gruvbox", "nord"}
+	got := completeNames(names, "EVER")
+	if len(got) != 2 || got[0] != "Everblush" || got[1] != "everforest" {
+		t.Errorf("completeNames(EVER) = %q", got)
+	}
+	if got := completeNames(names, ""); len(got) != len(names) {
+		t.Errorf("completeNames of an empty prefix = %q, want every name", got)
+	}
+	if got := completeNames(names, "x"); got != nil {
+		t.Errorf("completeNames(x) = %q, want none", got)
+	}
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/cover"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minCoverRows is the smallest cover worth drawing.
+const minCoverRows = 4
+
+type coverRenderedMsg struct{ cover renderedCover }
+
+// renderedCover is a cover scaled and encoded for one size of the info
+// pane. That is too slow to do while drawing, so renderCover does it in
+// the background and View only shows the result.
+type renderedCover struct {
+	path    string
+	cols    int
+	rows    int
+	graphic string
+	lines   []string
+}
+
+// coverSize returns the cells a cover takes at the top of an info pane of
+// the given size. Cells are about twice as tall as wide, so a square cover
+// is twice as many columns as rows.
+func coverSize(width, height int) (int, int, bool) {
+	rows := min((width-4)/2, (height-3)/2)
+	return rows * 2, rows, rows >= minCoverRows
+}
+
+// hasCover reports whether the info pane has a cover to draw for the song
+// it shows.
+func (m Model) hasCover() bool {
+	switch {
+	case m.Cover == cover.Off, m.details.cover == nil, m.details.path != m.infoSong():
+		return false
+	case m.Cover == cover.Blocks:
+		return !colorless()
+	}
+	return true
+}
+
+// graphicCover reports whether a kitty or sixel cover is on screen.
+func (m Model) graphicCover() bool {
+	if m.Cover != cover.Kitty && m.Cover != cover.Sixel || !m.hasCover() {
+		return false
+	}
+	i := m.layout.index(infoPane)
+	if i < 0 || m.columnWidths() == nil && m.layout.focus != i+1 {
+		return false
+	}
+	cols, rows, ok := coverSize(m.paneWidth(infoPane), m.browserHeight())
+	if !ok {
+		return false
+	}
+	_, _, ready := m.coverImage(cols, rows)
+	return ready
+}
+
+// renderCover starts scaling and encoding the cover whenever the info
+// pane shows another one or changes size.
+func (m *Model) renderCover() tea.Cmd {
+	if m.renderingCover || m.layout.index(infoPane) < 0 || !m.hasCover() {
+		return nil
+	}
+	cols, rows, ok := coverSize(m.paneWidth(infoPane), m.browserHeight())
+	if !ok {
+		return nil
+	}
+	if _, _, ready := m.coverImage(cols, rows); ready {
+		return nil
+	}
+
+	m.renderingCover = true
+	img, protocol, profile := m.details.cover, m.Cover, lipgloss.ColorProfile()
+	c := renderedCover{path: m.details.path, cols: cols, rows: rows}
+	return func() tea.Msg {
+		cellWidth, cellHeight := cover.CellSize()
+		switch protocol {
+		case cover.Kitty:
+			c.graphic = cover.KittyImage(img, cols, rows, cellWidth, cellHeight)
+		case cover.Sixel:
+			c.graphic = cover.SixelImage(img, cols, rows, cellWidth, cellHeight)
+		default:
+			c.lines = cover.BlockImage(img, cols, rows, profile)
+		}
+		return coverRenderedMsg{c}
+	}
+}
+
+// coverImage returns the cover as an image escape sequence, or as lines of
+// half blocks, once it has been rendered at this size.
+func (m Model) coverImage(cols, rows int) (string, []string, bool) {
+	c := m.coverArt
+	if c.path != m.details.path || c.cols != cols || c.rows != rows {
+		return "", nil, false
+	}
+	return c.graphic, c.lines, true
+}
+
+// placeCover draws a kitty or sixel cover over the blank cells the info
+// pane leaves for it, x columns from the left of the body. The image is
+// sent at the end of the last of those rows, and that row carries a hash
+// of all of them: when any of them is redrawn, which may wipe part of the
+// image, the last one is redrawn after it and brings the image back.
+func (m Model) placeCover(body string, x int) string {
+	cols, rows, _ := coverSize(m.paneWidth(infoPane), m.browserHeight())
+	graphic, _, _ := m.coverImage(cols, rows)
+	lines := strings.Split(body, "\n")
+	top, last := 2, 2+rows-1
+	if last >= len(lines) {
+		return body
+	}
+
+	h := fnv.New32a()
+	for _, line := range lines[top : last+1] {
+		h.Write([]byte(line))
+	}
+	lines[last] += fmt.Sprintf("\x1b_dicesong;%08x\x1b\\\x1b7\x1b[%dA\x1b[%dG%s\x1b8",
+		h.Sum32(), rows-1, x+3, graphic)
+	return strings.Join(lines, "\n")
+}
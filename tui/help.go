@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/charmbracelet/bubbletea"
+)
+
+type helpOverlay struct {
+	open   bool
+	offset int
+}
+
+// helpLines lays out the keymap the same way --help prints it. Section
+// titles are returned with an empty help column so they can be styled.
+func (m Model) helpLines() [][2]string {
+	sections := m.Keys.Sections()
+	width := keymap.KeysWidth(sections)
+
+	var lines [][2]string
+	for i, section := range sections {
+		if i > 0 {
+			lines = append(lines, [2]string{"", ""})
+		}
+		lines = append(lines, [2]string{section.Title + ":", ""})
+		for _, line := range section.Lines {
+			keys := keymap.DisplayKeys(line.Keys)
+			lines = append(lines, [2]string{
+				fmt.Sprintf("  %s%s", keys, strings.Repeat(" ", width-len([]rune(keys)))),
+				line.Help,
+			})
+		}
+	}
+	return lines
+}
+
+func (m *Model) updateHelp(msg tea.KeyMsg) {
+	count := len(m.helpLines())
+	visible := max(m.height-9, 1)
+	maxOffset := max(count-visible, 0)
+
+	switch m.Keys.Action(keymap.ScopeBrowser, msg.String()) {
+	case keymap.Help, keymap.Cancel, keymap.Quit:
+		m.help.open = false
+	case keymap.Up:
+		m.help.offset = max(m.help.offset-1, 0)
+	case keymap.Down:
+		m.help.offset = min(m.help.offset+1, maxOffset)
+	}
+}
+
+func (m Model) renderHelp(height int) string {
+	var content strings.Builder
+
+	content.WriteString(m.titleStyle().Render("  ? Keyboard shortcuts  ") + "\n")
+	content.WriteString(BrowserSeparatorStyle.Render(strings.Repeat("─", m.width)) + "\n")
+
+	lines := m.helpLines()
+	visibleRows := max(height-3, 1)
+	end := min(m.help.offset+visibleRows, len(lines))
+
+	for _, line := range lines[m.help.offset:end] {
+		keys, help := line[0], line[1]
+		if help == "" {
+			content.WriteString(BrowserItemDirStyle.Render("  "+fitWidth(keys, m.width-4, ellipsisEnd)) + "\n")
+			continue
+		}
+		help = fitWidth(help, m.width-len([]rune(keys))-5, m.nameEllipsis())
+		content.WriteString("  " + HelpKeyStyle.Render(keys) + "  " + BrowserItemStyle.Render(help) + "\n")
+	}
+
+	return BrowserBoxStyle.
+		Width(m.width).
+		Height(height).
+		Render(content.String())
+}
@@ -0,0 +1,295 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/cover"
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/library"
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/Gylmynnn/dicesong/playlist"
+	"github.com/Gylmynnn/dicesong/ratings"
+	"github.com/Gylmynnn/dicesong/tags"
+	"github.com/charmbracelet/bubbletea"
+)
+
+const infoLabelWidth = 13
+
+type detailsLoadedMsg struct{ details trackDetails }
+
+// trackDetails is what the info pane shows about a file that is too slow
+// to read on every frame.
+type trackDetails struct {
+	path    string
+	size    int64
+	modTime time.Time
+	tags    tags.Tags
+	info    tags.Info
+	cover   image.Image
+	err     error
+}
+
+func loadDetails(ix *library.Index, path string, withCover bool) tea.Cmd {
+	return func() tea.Msg {
+		d := trackDetails{path: path}
+		stat, err := os.Stat(path)
+		if err != nil {
+			d.err = err
+			return detailsLoadedMsg{d}
+		}
+		d.size, d.modTime = stat.Size(), stat.ModTime()
+		d.tags, _ = tags.Read(path)
+		ix.Probe([]string{path})
+		d.info, _ = ix.Info(path)
+		if withCover {
+			d.cover, _ = cover.Find(path)
+		}
+		return detailsLoadedMsg{d}
+	}
+}
+
+// infoSong is the song the info pane describes: the one under the cursor
+// of the browser or queue, or the playing one when there is none or the
+// pane was switched to it.
+func (m Model) infoSong() string {
+	i := m.layout.index(infoPane)
+	if i < 0 {
+		return ""
+	}
+	if !m.layout.panes[i].playing {
+		if p := m.focusedPane(); p != nil && p.kind == queuePane && p.cursor < len(m.queue) {
+			return m.queue[p.cursor]
+		}
+		if song := m.selectedSong(); song != "" && !playlist.IsPlaylist(song) {
+			return song
+		}
+	}
+	return m.playingSong()
+}
+
+// loadInfo reads the details of the song the info pane shows whenever it
+// changes to another one.
+func (m *Model) loadInfo() tea.Cmd {
+	if m.loadingDetails {
+		return nil
+	}
+	song := m.infoSong()
+	if song == "" || song == m.details.path {
+		return nil
+	}
+	m.loadingDetails = true
+	return loadDetails(m.library, song, m.Cover != cover.Off)
+}
+
+func (m *Model) infoAction(p *sidePane, action keymap.Action) bool {
+	switch action {
+	case keymap.Up:
+		m.scrollInfo(p, -1)
+	case keymap.Down:
+		m.scrollInfo(p, 1)
+	case keymap.Select, keymap.Open:
+		p.playing = !p.playing
+		p.offset = 0
+	case keymap.Back:
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *Model) scrollInfo(p *sidePane, delta int) {
+	width := m.paneWidth(infoPane)
+	count := len(m.infoLines(width))
+	maxOffset := max(count-m.infoRows(width, m.browserHeight()), 0)
+	p.offset = min(max(p.offset+delta, 0), maxOffset)
+}
+
+// infoRows returns how many detail lines fit below the title and cover.
+func (m Model) infoRows(width, height int) int {
+	rows := max(height-3, 1)
+	if _, coverRows, ok := coverSize(width, height); ok && m.hasCover() {
+		rows = max(rows-coverRows-1, 1)
+	}
+	return rows
+}
+
+// infoLines lays out the details as label and value pairs wrapped to
+// width. Section titles have no value.
+func (m Model) infoLines(width int) [][2]string {
+	song := m.infoSong()
+	if song == "" {
+		return [][2]string{{"No song selected", ""}}
+	}
+
+	var lines [][2]string
+	section := func(title string) {
+		if len(lines) > 0 {
+			lines = append(lines, [2]string{"", ""})
+		}
+		lines = append(lines, [2]string{title, ""})
+	}
+	add := func(label, value string) {
+		if value == "" {
+			return
+		}
+		for _, paragraph := range strings.Split(strings.ReplaceAll(value, "\r", ""), "\n") {
+			if paragraph == "" {
+				continue
+			}
+			for _, line := range wrapWidth(paragraph, width-infoLabelWidth-4) {
+				lines = append(lines, [2]string{label, line})
+				label = ""
+			}
+		}
+	}
+
+	section("File")
+	add("Path", song)
+	if song != m.details.path {
+		add("", "Loading...")
+		return lines
+	}
+	d := m.details
+	if d.err != nil {
+		add("Error", d.err.Error())
+		return lines
+	}
+	add("Size", formatSize(d.size))
+	add("Modified", d.modTime.Format("2006-01-02 15:04"))
+
+	section("Stream")
+	add("Codec", formatCodec(song, d.info))
+	add("Duration", formatLength(d.info.Duration))
+	if d.info.SampleRate > 0 {
+		add("Sample rate", fmt.Sprintf("%d Hz", d.info.SampleRate))
+	}
+	if d.info.Channels > 0 {
+		add("Channels", fmt.Sprint(d.info.Channels))
+	}
+	if d.info.BitDepth > 0 {
+		add("Bit depth", fmt.Sprintf("%d bit", d.info.BitDepth))
+	}
+	if d.info.Bitrate > 0 {
+		add("Bitrate", fmt.Sprintf("%d kbps", d.info.Bitrate))
+	}
+	if format, ok := player.CurrentFormat(); ok && song == m.playingSong() && !m.loading {
+		add("Decoder", fmt.Sprintf("%d Hz, %d ch, %d bit",
+			format.SampleRate, format.NumChannels, format.Precision*8))
+	}
+
+	gains := d.tags.ReplayGain()
+	if len(gains) > 0 {
+		section("ReplayGain")
+		for _, key := range sortedKeys(gains) {
+			add(gainLabel(key), gains[key])
+		}
+	}
+
+	if len(d.tags.Raw) > len(gains) {
+		section("Tags")
+		for _, key := range sortedKeys(d.tags.Raw) {
+			if _, ok := gains[strings.ToUpper(key)]; !ok {
+				add(key, d.tags.Raw[key])
+			}
+		}
+	}
+
+	section("Statistics")
+	s := m.stats.Get(song)
+	add("Plays", fmt.Sprint(s.Plays))
+	if !s.LastPlayed.IsZero() {
+		add("Last played", s.LastPlayed.Format("2006-01-02 15:04"))
+	}
+	if added := m.library.Track(song).Added; !added.IsZero() {
+		add("Added", added.Format("2006-01-02"))
+	}
+	r := m.ratings.Get(song)
+	add("Rating", ratings.Stars(r.Rating))
+	if r.Favorite {
+		add("Favorite", "yes")
+	}
+	return lines
+}
+
+func (m Model) renderInfo(p sidePane, height int) string {
+	var content strings.Builder
+
+	title := "\uf05a Track info"
+	if p.playing {
+		title = "\uf05a Now playing"
+	}
+	content.WriteString(m.titleStyle().Render("  "+fitWidth(title, m.width-4, ellipsisEnd)+"  ") + "\n")
+	content.WriteString(BrowserSeparatorStyle.Render(strings.Repeat("─", m.width)) + "\n")
+
+	lines := m.infoLines(m.width)
+	visibleRows := m.infoRows(m.width, height)
+	if cols, rows, ok := coverSize(m.width, height); ok && m.hasCover() {
+		// Kitty and sixel covers are drawn over these blank rows later,
+		// and all covers once renderCover has them.
+		_, blocks, _ := m.coverImage(cols, rows)
+		for i := range rows {
+			if blocks != nil {
+				content.WriteString("  " + blocks[i])
+			}
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+	}
+	offset := min(p.offset, max(len(lines)-visibleRows, 0))
+	end := min(offset+visibleRows, len(lines))
+
+	for _, line := range lines[offset:end] {
+		label, value := line[0], line[1]
+		if value == "" {
+			content.WriteString(BrowserItemDirStyle.Render("  "+fitWidth(label, m.width-4, ellipsisEnd)) + "\n")
+			continue
+		}
+		label = padWidth(fitWidth(label, infoLabelWidth, ellipsisEnd), infoLabelWidth)
+		content.WriteString("  " + BrowserColumnStyle.Render(label) + " " + BrowserItemStyle.Render(value) + "\n")
+	}
+
+	return BrowserBoxStyle.
+		Width(m.width).
+		Height(height).
+		Render(content.String())
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// gainLabel turns REPLAYGAIN_TRACK_GAIN into "Track gain".
+func gainLabel(key string) string {
+	label := strings.ToLower(strings.TrimPrefix(key, "REPLAYGAIN_"))
+	label = strings.ReplaceAll(label, "_", " ")
+	if rest, ok := strings.CutPrefix(label, "r128"); ok {
+		return "R128" + rest
+	}
+	if label == "" {
+		return key
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
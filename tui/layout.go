@@ -0,0 +1,250 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	defaultPaneWidth = 40
+	minPaneWidth     = 20
+	maxPaneWidth     = 70
+	paneWidthStep    = 5
+
+	// Below this width the panes take turns filling the screen instead of
+	// sitting side by side.
+	minSplitWidth = 100
+)
+
+type paneKind string
+
+const (
+	queuePane      paneKind = "queue"
+	infoPane       paneKind = "info"
+	lyricsPane     paneKind = "lyrics"
+	visualizerPane paneKind = "visualizer"
+)
+
+var paneKinds = []paneKind{queuePane, infoPane, lyricsPane, visualizerPane}
+
+type sidePane struct {
+	kind   paneKind
+	cursor int
+	offset int
+	// playing makes the info pane follow the playing song instead of the
+	// cursor.
+	playing bool
+	// scrolled stops the lyrics pane following the line being sung.
+	scrolled bool
+}
+
+// layout holds the panes shown to the right of the main column. Focus 0
+// is the main column and focus i is panes[i-1].
+type layout struct {
+	panes []sidePane
+	focus int
+	width int
+}
+
+func newLayout(names []string, width int) layout {
+	l := layout{width: width}
+	if l.width == 0 {
+		l.width = defaultPaneWidth
+	}
+	for _, name := range names {
+		if kind := paneKind(name); slices.Contains(paneKinds, kind) {
+			l.panes = append(l.panes, sidePane{kind: kind})
+		}
+	}
+	return l
+}
+
+func (l layout) names() []string {
+	names := make([]string, len(l.panes))
+	for i, p := range l.panes {
+		names[i] = string(p.kind)
+	}
+	return names
+}
+
+func (l layout) index(kind paneKind) int {
+	return slices.IndexFunc(l.panes, func(p sidePane) bool { return p.kind == kind })
+}
+
+// togglePane opens or closes a side pane. Panes without a cursor only
+// take focus when they would otherwise not be seen.
+func (m *Model) togglePane(kind paneKind) {
+	if i := m.layout.index(kind); i >= 0 {
+		m.layout.panes = slices.Delete(m.layout.panes, i, i+1)
+		if m.layout.focus > i {
+			m.layout.focus--
+		}
+		m.layout.focus = min(m.layout.focus, len(m.layout.panes))
+	} else {
+		p := sidePane{kind: kind}
+		if kind == queuePane {
+			moveCursor(&p.cursor, &p.offset, max(m.playingIndex, 0), len(m.queue), m.height-16)
+		}
+		m.layout.panes = append(m.layout.panes, p)
+		if kind == queuePane || m.columnWidths() == nil {
+			m.layout.focus = len(m.layout.panes)
+		}
+	}
+	saveState(*m)
+}
+
+func (m *Model) cycleFocus(delta int) {
+	n := len(m.layout.panes) + 1
+	m.layout.focus = (m.layout.focus + delta + n) % n
+}
+
+func (m *Model) resizePanes(delta int) {
+	m.layout.width = min(max(m.layout.width+delta, minPaneWidth), maxPaneWidth)
+	saveState(*m)
+}
+
+func (m *Model) focusedPane() *sidePane {
+	if m.layout.focus == 0 || m.layout.focus > len(m.layout.panes) {
+		return nil
+	}
+	return &m.layout.panes[m.layout.focus-1]
+}
+
+// sideAction runs an action in the focused side pane and reports whether
+// the pane took it.
+func (m *Model) sideAction(action keymap.Action) bool {
+	p := m.focusedPane()
+	if p == nil {
+		return false
+	}
+	if action == keymap.Cancel {
+		m.layout.focus = 0
+		return true
+	}
+	switch p.kind {
+	case queuePane:
+		return m.queueAction(p, action)
+	case infoPane:
+		return m.infoAction(p, action)
+	case lyricsPane:
+		return m.lyricsAction(p, action)
+	case visualizerPane:
+		return m.visualizerAction(action)
+	}
+	return false
+}
+
+// columnWidths returns the width of the main column followed by those of
+// the side panes, or nil when they do not fit side by side.
+func (m Model) columnWidths() []int {
+	n := len(m.layout.panes)
+	if n == 0 || m.width < minSplitWidth {
+		return nil
+	}
+	side := (m.width*m.layout.width/100 - n) / n
+	widths := []int{m.width - n*(side+1)}
+	for range n {
+		widths = append(widths, side)
+	}
+	return widths
+}
+
+// paneWidth returns the width the side pane of the given kind is drawn at.
+func (m Model) paneWidth(kind paneKind) int {
+	if widths := m.columnWidths(); widths != nil {
+		if i := m.layout.index(kind); i >= 0 {
+			return widths[i+1]
+		}
+	}
+	return m.width
+}
+
+// columnAt returns the focus index of the column at x.
+func (m Model) columnAt(x int) int {
+	widths := m.columnWidths()
+	if widths == nil {
+		return m.layout.focus
+	}
+	left := 0
+	for i, w := range widths {
+		if x < left+w {
+			return i
+		}
+		left += w + 1
+	}
+	return len(widths) - 1
+}
+
+// sized returns a copy of m that renders into a column of the given width.
+func (m Model) sized(width int, focused bool) Model {
+	m.width = width
+	m.unfocused = !focused
+	return m
+}
+
+func (m Model) titleStyle() lipgloss.Style {
+	if m.unfocused {
+		return BrowserPathDimStyle
+	}
+	return BrowserPathStyle
+}
+
+func (m Model) renderBody(height int) string {
+	widths := m.columnWidths()
+	if widths == nil {
+		if p := m.focusedPane(); p != nil {
+			body := m.renderSide(*p, height)
+			if p.kind == infoPane && m.graphicCover() {
+				body = m.placeCover(body, 0)
+			}
+			return body
+		}
+		return m.renderMain(height)
+	}
+
+	columns := []string{m.sized(widths[0], m.layout.focus == 0).renderMain(height)}
+	separator := BrowserSeparatorStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+	coverX, x := 0, widths[0]+1
+	for i, p := range m.layout.panes {
+		if p.kind == infoPane {
+			coverX = x
+		}
+		x += widths[i+1] + 1
+		columns = append(columns, separator, m.sized(widths[i+1], m.layout.focus == i+1).renderSide(p, height))
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	if m.graphicCover() {
+		body = m.placeCover(body, coverX)
+	}
+	return body
+}
+
+func (m Model) renderMain(height int) string {
+	switch {
+	case m.help.open:
+		return m.renderHelp(height)
+	case m.picker.open:
+		return m.renderPicker(height)
+	case m.pane.open:
+		return m.renderPlaylists(height)
+	default:
+		return m.renderBrowser(height)
+	}
+}
+
+func (m Model) renderSide(p sidePane, height int) string {
+	switch p.kind {
+	case queuePane:
+		return m.renderQueue(p, height)
+	case infoPane:
+		return m.renderInfo(p, height)
+	case lyricsPane:
+		return m.renderLyrics(p, height)
+	case visualizerPane:
+		return m.renderVisualizer(height)
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/lyrics"
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/charmbracelet/bubbletea"
+)
+
+const lyricsOffsetStep = 100 * time.Millisecond
+
+type lyricsLoadedMsg struct{ lyrics songLyrics }
+
+// songLyrics are the lyrics of the playing song.
+type songLyrics struct {
+	path   string
+	lyrics lyrics.Lyrics
+	err    error
+}
+
+// lyricRow is one row of the lyrics pane, part of the lyric line at index
+// line once long lines are wrapped.
+type lyricRow struct {
+	line int
+	text string
+}
+
+func readLyrics(path string) tea.Cmd {
+	return func() tea.Msg {
+		l, err := lyrics.Load(path)
+		return lyricsLoadedMsg{songLyrics{path: path, lyrics: l, err: err}}
+	}
+}
+
+// loadLyrics reads the lyrics of the playing song whenever it changes
+// while the lyrics pane is open.
+func (m *Model) loadLyrics() tea.Cmd {
+	if m.loadingLyrics || m.layout.index(lyricsPane) < 0 {
+		return nil
+	}
+	song := m.playingSong()
+	if song == "" || song == m.lyrics.path {
+		return nil
+	}
+	m.loadingLyrics = true
+	return readLyrics(song)
+}
+
+func (m *Model) handleLyricsLoaded(msg lyricsLoadedMsg) tea.Cmd {
+	m.loadingLyrics = false
+	m.lyrics = msg.lyrics
+	m.lyricsOffset = 0
+	if i := m.layout.index(lyricsPane); i >= 0 {
+		m.layout.panes[i].offset = 0
+		m.layout.panes[i].scrolled = false
+	}
+	return m.loadLyrics()
+}
+
+func (m *Model) shiftLyrics(delta time.Duration) {
+	m.lyricsOffset += delta
+}
+
+// playingPosition converts the progress from GetProgress, which counts
+// samples of the decoded song, to time.
+func (m Model) playingPosition() time.Duration {
+	format, ok := player.CurrentFormat()
+	if !ok {
+		return 0
+	}
+	return format.SampleRate.D(int(m.progress))
+}
+
+// currentLyric returns the index of the line being sung, or -1.
+func (m Model) currentLyric() int {
+	if m.lyrics.path == "" || m.lyrics.path != m.playingSong() {
+		return -1
+	}
+	return m.lyrics.lyrics.Current(m.playingPosition() + m.lyricsOffset)
+}
+
+func (m Model) lyricRows(width int) []lyricRow {
+	if m.lyrics.path != m.playingSong() {
+		return nil
+	}
+	var rows []lyricRow
+	for i, line := range m.lyrics.lyrics.Lines {
+		if line.Text == "" {
+			rows = append(rows, lyricRow{line: i})
+			continue
+		}
+		for _, text := range wrapWidth(line.Text, width-4) {
+			rows = append(rows, lyricRow{line: i, text: text})
+		}
+	}
+	return rows
+}
+
+// lyricsTop returns the first row shown: the one scrolled to, or one that
+// keeps the current line in the middle of the pane.
+func (m Model) lyricsTop(p sidePane, rows []lyricRow, visible int) int {
+	maxTop := max(len(rows)-visible, 0)
+	current := m.currentLyric()
+	if p.scrolled || current < 0 {
+		return min(p.offset, maxTop)
+	}
+	row := slices.IndexFunc(rows, func(r lyricRow) bool { return r.line == current })
+	return min(max(row-(visible-1)/2, 0), maxTop)
+}
+
+// lyricsAction runs an action in the lyrics pane. Scrolling stops it
+// following the song until Enter.
+func (m *Model) lyricsAction(p *sidePane, action keymap.Action) bool {
+	switch action {
+	case keymap.Up:
+		m.scrollLyrics(p, -1)
+	case keymap.Down:
+		m.scrollLyrics(p, 1)
+	case keymap.Select, keymap.Open:
+		p.scrolled = false
+	case keymap.Back:
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *Model) scrollLyrics(p *sidePane, delta int) {
+	visible := max(m.browserHeight()-3, 1)
+	rows := m.lyricRows(m.paneWidth(lyricsPane))
+	top := m.lyricsTop(*p, rows, visible)
+	p.offset = min(max(top+delta, 0), max(len(rows)-visible, 0))
+	p.scrolled = m.lyrics.lyrics.Synced
+}
+
+// clickLyrics seeks to the synced line at the given row of the pane.
+func (m *Model) clickLyrics(p *sidePane, row int) {
+	visible := max(m.browserHeight()-3, 1)
+	rows := m.lyricRows(m.paneWidth(lyricsPane))
+	index := m.lyricsTop(*p, rows, visible) + row
+	format, ok := player.CurrentFormat()
+	if !m.lyrics.lyrics.Synced || index >= len(rows) || !ok || m.loading || m.total <= 0 {
+		return
+	}
+
+	line := m.lyrics.lyrics.Lines[rows[index].line]
+	at := max(line.Time-m.lyrics.lyrics.Offset-m.lyricsOffset, 0)
+	if err := m.seek(float64(format.SampleRate.N(at)) / m.total); err != nil {
+		m.errorMsg = "Seek failed: " + err.Error()
+		return
+	}
+	p.scrolled = false
+}
+
+func (m Model) renderLyrics(p sidePane, height int) string {
+	var content strings.Builder
+
+	title := "\uf130 Lyrics"
+	if m.lyricsOffset != 0 {
+		title += fmt.Sprintf(" (%+.1fs)", m.lyricsOffset.Seconds())
+	}
+	content.WriteString(m.titleStyle().Render("  "+fitWidth(title, m.width-4, ellipsisEnd)+"  ") + "\n")
+	content.WriteString(BrowserSeparatorStyle.Render(strings.Repeat("─", m.width)) + "\n")
+
+	message := ""
+	song := m.playingSong()
+	switch {
+	case song == "":
+		message = "Nothing playing"
+	case song != m.lyrics.path:
+		message = "Loading..."
+	case errors.Is(m.lyrics.err, lyrics.ErrNotFound), m.lyrics.err == nil && len(m.lyrics.lyrics.Lines) == 0:
+		message = "No lyrics found"
+	case m.lyrics.err != nil:
+		message = m.lyrics.err.Error()
+	}
+	if message != "" {
+		for _, line := range wrapWidth(message, m.width-4) {
+			content.WriteString("  " + BrowserColumnStyle.Render(line) + "\n")
+		}
+	} else {
+		visible := max(height-3, 1)
+		rows := m.lyricRows(m.width)
+		current := m.currentLyric()
+		top := m.lyricsTop(p, rows, visible)
+		for _, row := range rows[top:min(top+visible, len(rows))] {
+			style := BrowserItemStyle
+			switch {
+			case row.line == current:
+				style = BrowserItemPlayingStyle
+			case row.line < current:
+				style = BrowserColumnStyle
+			}
+			content.WriteString("  " + style.Render(row.text) + "\n")
+		}
+	}
+
+	return BrowserBoxStyle.
+		Width(m.width).
+		Height(height).
+		Render(content.String())
+}
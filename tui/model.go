@@ -2,44 +2,54 @@ package tui
 
 import (
 	"fmt"
-	"io/fs"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/Gylmynnn/dicesong/cover"
+	"github.com/Gylmynnn/dicesong/fuzzy"
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/library"
 	"github.com/Gylmynnn/dicesong/notifier"
 	"github.com/Gylmynnn/dicesong/player"
+	"github.com/Gylmynnn/dicesong/playlist"
+	"github.com/Gylmynnn/dicesong/query"
+	"github.com/Gylmynnn/dicesong/ratings"
+	"github.com/Gylmynnn/dicesong/smart"
 	"github.com/Gylmynnn/dicesong/state"
+	"github.com/Gylmynnn/dicesong/stats"
+	"github.com/Gylmynnn/dicesong/theme"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	everblushBg0     = lipgloss.Color("#141b1e")
-	everblushBg1     = lipgloss.Color("#232a2d")
-	everblushRed     = lipgloss.Color("#e57474")
-	everblushGreen   = lipgloss.Color("#8ccf7e")
-	everblushYellow  = lipgloss.Color("#e5c76b")
-	everblushBlue    = lipgloss.Color("#67b0e8")
-	everblushMagenta = lipgloss.Color("#c47fd5")
-	everblushCyan    = lipgloss.Color("#6cbfbf")
-	everblushFg      = lipgloss.Color("#dadada")
-	everblushGray    = lipgloss.Color("#5c6a72")
-)
-
 type (
 	tickMsg         struct{}
 	songFinishedMsg struct{}
 	songLoadedMsg   struct{ success bool }
+	libraryReadyMsg struct{}
 )
 
+type controlButton struct {
+	action keymap.Action
+	text   string
+}
+
 type fsEntry struct {
-	name  string
-	path  string
-	isDir bool
+	name       string
+	path       string
+	isDir      bool
+	isPlaylist bool
+	tags       string
+	matches    []int
+}
+
+func (e fsEntry) browsable() bool {
+	return e.isDir || e.isPlaylist
 }
 
 func listenForFinished(c chan bool) tea.Cmd {
@@ -49,6 +59,13 @@ func listenForFinished(c chan bool) tea.Cmd {
 	}
 }
 
+func waitForLibrary(ix *library.Index) tea.Cmd {
+	return func() tea.Msg {
+		<-ix.Ready()
+		return libraryReadyMsg{}
+	}
+}
+
 func listenForLoaded(c chan bool) tea.Cmd {
 	return func() tea.Msg {
 		ok := <-c
@@ -57,47 +74,95 @@ func listenForLoaded(c chan bool) tea.Cmd {
 }
 
 type Model struct {
-	width        int
-	height       int
-	errorMsg     string
-	musicRoot    string
-	currentPath  string
-	entries      []fsEntry
-	allSongs     []string
-	cursor       int
-	offset       int
-	playingIndex int
-	loading      bool
-	DoneChan     chan bool
-	LoadedChan   chan bool
-	PlayRequest  chan string
-	repeat       bool
-	shuffle      bool
-	lastPlay     time.Time
-	progress     float64
-	total        float64
-	searchMode   bool
-	searchQuery  string
+	width          int
+	height         int
+	errorMsg       string
+	musicRoot      string
+	currentPath    string
+	entries        []fsEntry
+	allSongs       []string
+	queue          []string
+	cursor         int
+	offset         int
+	playingIndex   int
+	loading        bool
+	DoneChan       chan bool
+	LoadedChan     chan bool
+	PlayRequest    chan string
+	Keys           *keymap.Keymap
+	Themes         []theme.Theme
+	themeName      string
+	repeat         bool
+	shuffle        bool
+	lastPlay       time.Time
+	progress       float64
+	total          float64
+	searchMode     bool
+	searchQuery    string
+	searchGlobal   bool
+	searchSource   []fsEntry
+	searchBase     string
+	searchErr      string
+	promptMode     bool
+	promptInput    string
+	promptLabel    string
+	promptSubmit   func(*Model, string) error
+	command        commandLine
+	ratings        *ratings.DB
+	tagWriter      *tagWriter
+	playlists      *playlist.Store
+	pane           playlistPane
+	picker         playlistPicker
+	help           helpOverlay
+	layout         layout
+	details        trackDetails
+	coverArt       renderedCover
+	unfocused      bool
+	click          lastClick
+	stats          *stats.DB
+	library        *library.Index
+	smartLists     *smart.Store
+	smartTracks    map[string][]string
+	view           libraryView
+	viewPath       []string
+	sorts          map[string]state.SortOrder
+	hideColumns    bool
+	probing        bool
+	loadingDetails bool
+	renderingCover bool
+	lyrics         songLyrics
+	lyricsOffset   time.Duration
+	loadingLyrics  bool
+	visual         visualizer
+	waveform       songWaveform
+	showWaveform   bool
+	loadingPeaks   bool
+	sleep          sleepTimer
+
+	WriteRatingTags bool
+	MiddleEllipsis  bool
+	Cover           cover.Protocol
+	SleepFade       time.Duration
 }
 
 func InitialModel() Model {
 	rand.New(rand.NewSource(time.Now().UnixNano()))
-	home, err := os.UserHomeDir()
+	musicRoot, err := library.DefaultRoot()
 	if err != nil {
 		panic("Failed to read home directory: " + err.Error())
 	}
 
-	musicRoot := filepath.Join(home, "Music")
-
-	entries, _ := readDir(musicRoot)
-	allSongs, _ := loadAllSongs(musicRoot)
+	allSongs, _ := library.Scan(musicRoot)
 	stateData := state.Load()
+	ratingDB := ratings.Open()
+	go ratingDB.Relink(allSongs)
+	statsDB := stats.Open()
 
-	return Model{
+	m := Model{
 		musicRoot:    musicRoot,
 		currentPath:  musicRoot,
-		entries:      entries,
 		allSongs:     allSongs,
+		queue:        allSongs,
 		cursor:       0,
 		playingIndex: -1,
 		loading:      false,
@@ -106,11 +171,33 @@ func InitialModel() Model {
 		DoneChan:     make(chan bool),
 		LoadedChan:   make(chan bool),
 		PlayRequest:  make(chan string, 1),
+		Keys:         keymap.Default(),
+		Themes:       theme.Builtin(),
+		themeName:    stateData.Theme,
 		progress:     0,
 		total:        1,
 		searchMode:   false,
 		searchQuery:  "",
+		ratings:      ratingDB,
+		tagWriter:    &tagWriter{pending: map[string]int{}},
+		playlists:    playlist.OpenStore(),
+		stats:        statsDB,
+		library:      library.Open(musicRoot, allSongs, ratingDB, statsDB),
+		smartLists:   smart.OpenStore(),
+		smartTracks:  map[string][]string{},
+		sorts:        stateData.Sorts,
+		hideColumns:  stateData.HideColumns,
+		layout:       newLayout(stateData.Panes, stateData.PaneWidth),
+		visual:       newVisualizer(stateData.Visualizer),
+		showWaveform: stateData.Waveform,
+		Cover:        cover.Detect(),
+		SleepFade:    DefaultSleepFade,
 	}
+	if m.sorts == nil {
+		m.sorts = map[string]state.SortOrder{}
+	}
+	m.entries = m.readEntries()
+	return m
 }
 
 func PlaybackManager(playRequest <-chan string, doneChan chan bool, loadedChan chan<- bool) {
@@ -126,10 +213,12 @@ func PlaybackManager(playRequest <-chan string, doneChan chan bool, loadedChan c
 }
 
 func (m Model) Init() tea.Cmd {
+	applyTheme(m.currentTheme())
 	return tea.Batch(
 		tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg { return tickMsg{} }),
 		listenForFinished(m.DoneChan),
 		listenForLoaded(m.LoadedChan),
+		waitForLibrary(m.library),
 	)
 }
 
@@ -141,167 +230,96 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 	case tea.KeyMsg:
-		if m.searchMode {
-			switch msg.String() {
-			case "esc":
-				m.searchMode = false
-				m.searchQuery = ""
-				m.entries, _ = readDir(m.currentPath)
-				m.cursor = 0
-				m.offset = 0
-			case "up", "k":
-				if m.cursor > 0 {
-					m.cursor--
-					if m.cursor < m.offset {
-						m.offset--
-					}
-				}
-			case "down", "j":
-				if m.cursor < len(m.entries)-1 {
-					m.cursor++
-					visibleRow := m.height - 16
-					if m.cursor >= m.offset+visibleRow {
-						m.offset++
-					}
-				}
-			case "backspace":
-				if len(m.searchQuery) > 0 {
-					m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
-					m.filterEntries()
-				}
-			case "enter":
-				selectedEntry := m.entries[m.cursor]
-				if selectedEntry.isDir {
-					m.currentPath = selectedEntry.path
-					m.entries, _ = readDir(m.currentPath)
-					m.cursor = 0
-					m.offset = 0
-					m.searchMode = false
-					m.searchQuery = ""
-				} else {
-					if m.loading || time.Since(m.lastPlay) < 300*time.Millisecond {
-						break
-					}
-					m.lastPlay = time.Now()
-					m.loading = true
-					m.playingIndex = findSongIndex(m.allSongs, selectedEntry.path)
-					m.PlayRequest <- selectedEntry.path
-					saveState(m)
-					m.searchMode = false
-					m.searchQuery = ""
-					m.entries, _ = readDir(m.currentPath)
-					m.cursor = 0
-					m.offset = 0
-				}
-			default:
-				if len(msg.String()) == 1 {
-					m.searchQuery += msg.String()
-					m.filterEntries()
-				}
+		if m.promptMode {
+			m.updatePrompt(msg)
+		} else if m.command.open {
+			cmd = m.updateCommand(msg)
+		} else if m.help.open {
+			m.updateHelp(msg)
+		} else if m.picker.open {
+			m.updatePicker(msg)
+		} else if m.searchMode {
+			m.updateSearch(msg)
+		} else if m.layout.focus > 0 {
+			if !m.sideAction(m.Keys.Action(keymap.ScopePane, msg.String())) {
+				cmd = m.browserAction(m.Keys.Action(keymap.ScopeBrowser, msg.String()))
 			}
+		} else if m.pane.open && m.updatePlaylistPane(msg) {
+			break
 		} else {
-			switch msg.String() {
-			case "ctrl+c", "q":
-				return m, tea.Quit
-			case "/":
-				m.searchMode = true
-			case "up", "k":
-				if m.cursor > 0 {
-					m.cursor--
-					if m.cursor < m.offset {
-						m.offset--
-					}
-				}
-			case "down", "j":
-				if m.cursor < len(m.entries)-1 {
-					m.cursor++
-					visibleRow := m.height - 16
-					if m.cursor >= m.offset+visibleRow {
-						m.offset++
-					}
-				}
-			case "right", "l":
-				selectedEntry := m.entries[m.cursor]
-				if selectedEntry.isDir {
-					m.currentPath = selectedEntry.path
-					m.entries, _ = readDir(m.currentPath)
-					m.cursor = 0
-					m.offset = 0
-				}
-			case "enter":
-				if m.loading || time.Since(m.lastPlay) < 300*time.Millisecond {
-					break
-				}
-				selectedEntry := m.entries[m.cursor]
-				if selectedEntry.isDir {
-					m.currentPath = selectedEntry.path
-					m.entries, _ = readDir(m.currentPath)
-					m.cursor = 0
-					m.offset = 0
-				} else {
-					m.lastPlay = time.Now()
-					m.loading = true
-					m.playingIndex = findSongIndex(m.allSongs, selectedEntry.path)
-					m.PlayRequest <- selectedEntry.path
-					saveState(m)
-				}
-			case "backspace", "left", "h":
-				parentDir := filepath.Dir(m.currentPath)
-				if parentDir != "." && parentDir != "" && strings.HasPrefix(m.currentPath, m.musicRoot) && m.currentPath != m.musicRoot {
-					m.currentPath = parentDir
-					m.entries, _ = readDir(m.currentPath)
-					m.cursor = 0
-					m.offset = 0
-				}
-			case "p":
-				player.TogglePause()
-				if m.playingIndex >= 0 && m.playingIndex < len(m.allSongs) {
-					notifier.Playback(filepath.Base(m.allSongs[m.playingIndex]), player.IsPaused())
-				}
-			case "n":
-				if m.playingIndex < len(m.allSongs)-1 && !m.loading {
-					m.loading = true
-					m.playingIndex++
-					m.PlayRequest <- m.allSongs[m.playingIndex]
-					saveState(m)
-				}
-			case "b":
-				if m.playingIndex > 0 && !m.loading {
-					m.loading = true
-					m.playingIndex--
-					m.PlayRequest <- m.allSongs[m.playingIndex]
-					saveState(m)
-				}
-			case "r":
-				m.repeat = !m.repeat
-				saveState(m)
-			case "s":
-				m.shuffle = !m.shuffle
-				saveState(m)
-			}
+			cmd = m.browserAction(m.Keys.Action(keymap.ScopeBrowser, msg.String()))
 		}
 
+	case tea.MouseMsg:
+		cmd = m.updateMouse(msg)
+
 	case tickMsg:
 		if m.playingIndex != -1 && !m.loading && !player.IsPaused() {
 			m.progress, m.total = player.GetProgress()
 		}
-		return m, tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg { return tickMsg{} })
+		m.updateVisualizer()
+		// These update m, so they run before m is returned; the order of
+		// the operands of a return statement is not specified.
+		cmd = tea.Batch(
+			tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg { return tickMsg{} }),
+			m.updateSleep(),
+			m.probeVisible(),
+			m.loadInfo(),
+			m.renderCover(),
+			m.loadLyrics(),
+			m.loadWaveform(),
+		)
+
+	case detailsLoadedMsg:
+		m.loadingDetails = false
+		m.details = msg.details
+		cmd = tea.Batch(m.loadInfo(), m.renderCover())
+
+	case coverRenderedMsg:
+		m.renderingCover = false
+		m.coverArt = msg.cover
+		cmd = m.renderCover()
+
+	case lyricsLoadedMsg:
+		cmd = m.handleLyricsLoaded(msg)
+
+	case waveformLoadedMsg:
+		cmd = m.handleWaveformLoaded(msg)
+
+	case infoProbedMsg:
+		m.probing = false
+		if m.sortingByDuration() {
+			m.refreshEntries()
+		}
+		cmd = m.probeVisible()
+
+	case tagWrittenMsg:
+		m.handleTagWritten(msg)
+
+	case libraryReadyMsg:
+		for name := range m.smartTracks {
+			m.refreshSmart(name)
+		}
+		if !m.searchMode {
+			m.refreshEntries()
+		}
 
 	case songLoadedMsg:
 		m.loading = false
 		if !msg.success {
-			m.errorMsg = "Error playing: " + filepath.Base(m.allSongs[m.playingIndex])
+			m.errorMsg = "Error playing: " + filepath.Base(m.playingSong())
 			notifier.Error(m.errorMsg)
 			return m, tea.Tick(time.Second*2, func(t time.Time) tea.Msg {
 				return songFinishedMsg{}
 			})
 		} else {
 			m.errorMsg = ""
-			if m.playingIndex >= 0 && m.playingIndex < len(m.allSongs) {
-				notifier.NowPlaying(filepath.Base(m.allSongs[m.playingIndex]), m.shuffle, m.repeat)
+			if song := m.playingSong(); song != "" {
+				m.stats.RecordPlay(song)
+				notifier.NowPlaying(filepath.Base(song), m.shuffle, m.repeat)
 			}
 		}
-		return m, listenForLoaded(m.LoadedChan)
+		cmd = tea.Batch(listenForLoaded(m.LoadedChan), m.sleepLoaded())
 
 	case songFinishedMsg:
 		m.progress, m.total = 0, 1
@@ -309,22 +327,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.errorMsg = ""
 
 		if m.repeat && m.playingIndex != -1 {
-			m.PlayRequest <- m.allSongs[m.playingIndex]
+			m.PlayRequest <- m.queue[m.playingIndex]
 			return m, tea.Batch(
 				listenForFinished(m.DoneChan),
 				listenForLoaded(m.LoadedChan),
 			)
-		} else if m.shuffle && len(m.allSongs) > 0 {
-			m.playingIndex = rand.Intn(len(m.allSongs))
-			m.PlayRequest <- m.allSongs[m.playingIndex]
+		} else if m.shuffle && len(m.queue) > 0 {
+			m.playingIndex = rand.Intn(len(m.queue))
+			m.PlayRequest <- m.queue[m.playingIndex]
 			return m, tea.Batch(
 				listenForFinished(m.DoneChan),
 				listenForLoaded(m.LoadedChan),
 			)
 		} else {
-			if m.playingIndex < len(m.allSongs)-1 {
+			if m.playingIndex < len(m.queue)-1 {
 				m.playingIndex++
-				m.PlayRequest <- m.allSongs[m.playingIndex]
+				m.PlayRequest <- m.queue[m.playingIndex]
 				return m, tea.Batch(
 					listenForFinished(m.DoneChan),
 					listenForLoaded(m.LoadedChan),
@@ -338,32 +356,244 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// browserAction runs a browser action, whether it came from a key or a
+// mouse click.
+func (m *Model) browserAction(action keymap.Action) tea.Cmd {
+	switch action {
+	case keymap.Quit:
+		return tea.Quit
+	case keymap.Help:
+		m.help = helpOverlay{open: true}
+	case keymap.Theme:
+		m.nextTheme()
+	case keymap.QueuePane:
+		m.togglePane(queuePane)
+	case keymap.InfoPane:
+		m.togglePane(infoPane)
+	case keymap.LyricsPane:
+		m.togglePane(lyricsPane)
+	case keymap.VisualizerPane:
+		m.togglePane(visualizerPane)
+	case keymap.Waveform:
+		m.toggleWaveform()
+	case keymap.LyricsEarlier:
+		m.shiftLyrics(lyricsOffsetStep)
+	case keymap.LyricsLater:
+		m.shiftLyrics(-lyricsOffsetStep)
+	case keymap.FocusNext:
+		m.cycleFocus(1)
+	case keymap.FocusPrevious:
+		m.cycleFocus(-1)
+	case keymap.PaneWider:
+		m.resizePanes(paneWidthStep)
+	case keymap.PaneNarrower:
+		m.resizePanes(-paneWidthStep)
+	case keymap.Command:
+		m.openCommand()
+	case keymap.Search:
+		m.searchMode = true
+		m.loadSearchSource()
+		m.filterEntries()
+	case keymap.Up:
+		m.moveBrowserCursor(-1)
+	case keymap.Down:
+		m.moveBrowserCursor(1)
+	case keymap.Open:
+		if len(m.entries) == 0 {
+			break
+		}
+		if selectedEntry := m.entries[m.cursor]; selectedEntry.browsable() {
+			m.openEntry(selectedEntry)
+		}
+	case keymap.Select:
+		if m.loading || time.Since(m.lastPlay) < 300*time.Millisecond || len(m.entries) == 0 {
+			break
+		}
+		selectedEntry := m.entries[m.cursor]
+		if selectedEntry.browsable() {
+			m.openEntry(selectedEntry)
+		} else {
+			m.playEntry(selectedEntry)
+		}
+	case keymap.Back:
+		m.goBack()
+	case keymap.View:
+		m.cycleView()
+	case keymap.Sort:
+		m.cycleSort()
+	case keymap.Reverse:
+		m.reverseSort()
+	case keymap.Columns:
+		m.hideColumns = !m.hideColumns
+		saveState(*m)
+	case keymap.Pause:
+		player.TogglePause()
+		if song := m.playingSong(); song != "" {
+			notifier.Playback(filepath.Base(song), player.IsPaused())
+		}
+	case keymap.Next:
+		if m.playingIndex < len(m.queue)-1 && !m.loading {
+			m.loading = true
+			m.playingIndex++
+			m.PlayRequest <- m.queue[m.playingIndex]
+			saveState(*m)
+		}
+	case keymap.Previous:
+		if m.playingIndex > 0 && !m.loading {
+			m.loading = true
+			m.playingIndex--
+			m.PlayRequest <- m.queue[m.playingIndex]
+			saveState(*m)
+		}
+	case keymap.Repeat:
+		m.repeat = !m.repeat
+		saveState(*m)
+	case keymap.Shuffle:
+		m.shuffle = !m.shuffle
+		saveState(*m)
+	case keymap.SaveQueue:
+		m.startSavePrompt(m.queue)
+	case keymap.Playlists:
+		m.togglePlaylistPane()
+	case keymap.AddToPlaylist:
+		if len(m.entries) > 0 {
+			m.openPicker(m.entries[m.cursor])
+		}
+	case keymap.RateActions[0], keymap.RateActions[1], keymap.RateActions[2],
+		keymap.RateActions[3], keymap.RateActions[4], keymap.RateActions[5]:
+		return m.rateSong(m.selectedSong(), slices.Index(keymap.RateActions, action))
+	case keymap.Favorite:
+		m.toggleFavorite(m.selectedSong())
+	case keymap.RateUp:
+		if song := m.playingSong(); song != "" {
+			return m.rateSong(song, m.ratings.Get(song).Rating+1)
+		}
+	case keymap.RateDown:
+		if song := m.playingSong(); song != "" {
+			return m.rateSong(song, m.ratings.Get(song).Rating-1)
+		}
+	case keymap.FavoritePlaying:
+		m.toggleFavorite(m.playingSong())
+	}
+	return nil
+}
+
+func (m *Model) updateSearch(msg tea.KeyMsg) {
+	if msg.Type == tea.KeyBackspace {
+		if runes := []rune(m.searchQuery); len(runes) > 0 {
+			m.searchQuery = string(runes[:len(runes)-1])
+			m.filterEntries()
+		}
+		return
+	}
+	if m.searchAction(m.Keys.Action(keymap.ScopeSearch, msg.String())) {
+		return
+	}
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		m.searchQuery += string(msg.Runes)
+		m.filterEntries()
+	}
+}
+
+// searchAction runs a search action and reports whether there was one.
+func (m *Model) searchAction(action keymap.Action) bool {
+	switch action {
+	case keymap.Cancel:
+		m.searchMode = false
+		m.searchQuery = ""
+		m.entries = m.readEntries()
+		m.cursor = 0
+		m.offset = 0
+	case keymap.Up:
+		m.moveBrowserCursor(-1)
+	case keymap.Down:
+		m.moveBrowserCursor(1)
+	case keymap.SearchSave:
+		m.startSavePrompt(m.entrySongs())
+	case keymap.SearchScope:
+		m.searchGlobal = !m.searchGlobal
+		m.loadSearchSource()
+		m.filterEntries()
+	case keymap.SearchEnqueue:
+		if len(m.entries) > 0 && !m.entries[m.cursor].browsable() {
+			m.enqueue(m.entries[m.cursor].path)
+		}
+	case keymap.Select:
+		if len(m.entries) == 0 {
+			break
+		}
+		selectedEntry := m.entries[m.cursor]
+		if selectedEntry.browsable() {
+			m.openEntry(selectedEntry)
+			m.searchMode = false
+			m.searchQuery = ""
+		} else {
+			if m.loading || time.Since(m.lastPlay) < 300*time.Millisecond {
+				break
+			}
+			if index := findSongIndex(m.allSongs, selectedEntry.path); m.searchGlobal && index >= 0 {
+				m.playQueue(m.allSongs, index)
+			} else {
+				m.playEntry(selectedEntry)
+			}
+			m.searchMode = false
+			m.searchQuery = ""
+			m.entries = m.readEntries()
+			m.cursor = 0
+			m.offset = 0
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *Model) moveBrowserCursor(delta int) {
+	moveCursor(&m.cursor, &m.offset, delta, len(m.entries), m.height-16)
+}
+
 func (m Model) View() string {
 	if m.width == 0 || m.height == 0 {
 		return ""
 	}
 
-	headerHeight := 3
-	playerBarHeight := 3
-	browserHeight := m.height - headerHeight - playerBarHeight
+	browserHeight := m.browserHeight()
 
 	header := m.renderHeader()
-	browser := m.renderBrowser(browserHeight)
+	if m.Cover == cover.Kitty && !m.graphicCover() {
+		// Kitty images stay on screen until deleted.
+		header = cover.KittyDelete + header
+	}
+	browser := m.renderBody(browserHeight)
 	playerBar := m.renderPlayerBar()
 
 	return lipgloss.JoinVertical(lipgloss.Top, header, browser, playerBar)
 }
 
+func (m Model) browserHeight() int {
+	headerHeight := 3
+	playerBarHeight := 3
+	return m.height - headerHeight - playerBarHeight
+}
+
 func (m Model) renderHeader() string {
+	songCount := HeaderInfoStyle.Render(fmt.Sprintf("%d Songs", len(m.allSongs)))
+	available := m.width - lipgloss.Width(songCount) - 6
+
+	// Long input is cut at the start so the end being typed stays visible.
+	fitInput := func(prefix, input string) string {
+		return prefix + fitWidth(input, available-lipgloss.Width(prefix), ellipsisStart)
+	}
+
 	var titleContent string
-	if m.searchMode {
-		titleContent = HeaderTitleStyle.Render(fmt.Sprintf("    DICESONG - Search: %s", m.searchQuery))
+	if m.promptMode {
+		titleContent = HeaderTitleStyle.Render(fitInput(fmt.Sprintf("    DICESONG - %s: ", m.promptLabel), m.promptInput))
+	} else if m.searchMode {
+		titleContent = HeaderTitleStyle.Render(fitInput(fmt.Sprintf("    DICESONG - %s: ", m.searchLabel()), m.searchQuery+m.searchErr))
 	} else {
 		titleContent = HeaderTitleStyle.Render("    DICESONG  ")
 	}
 
-	songCount := HeaderInfoStyle.Render(fmt.Sprintf("%d Songs", len(m.allSongs)))
-
 	titleWidth := lipgloss.Width(titleContent)
 	infoWidth := lipgloss.Width(songCount)
 	spacerWidth := max(m.width-titleWidth-infoWidth-4, 0)
@@ -382,18 +612,15 @@ func (m Model) renderHeader() string {
 func (m Model) renderBrowser(height int) string {
 	var content strings.Builder
 
-	home, _ := os.UserHomeDir()
-	displayPath := m.currentPath
-	if after, ok := strings.CutPrefix(m.currentPath, home); ok {
-		displayPath = "~" + after
-	}
-
-	pathLine := BrowserPathStyle.Render("  󱍙 " + displayPath + "  ")
+	sortLabel := m.sortLabel()
+	displayPath := fitWidth(m.displayPath(), m.width-8-lipgloss.Width(sortLabel), ellipsisStart)
+	pathLine := m.titleStyle().Render("  󱍙 " + displayPath + "  " + sortLabel)
 	content.WriteString(pathLine + "\n")
 	content.WriteString(BrowserSeparatorStyle.Render(strings.Repeat("─", m.width)) + "\n")
 
 	visibleRows := max(height-3, 1)
 	end := min(m.offset+visibleRows, len(m.entries))
+	cols := m.visibleColumns()
 
 	for i := m.offset; i < end; i++ {
 		entry := m.entries[i]
@@ -401,17 +628,31 @@ func (m Model) renderBrowser(height int) string {
 		icon := " "
 		if entry.isDir {
 			icon = "󱍙 "
+		} else if entry.isPlaylist {
+			icon = "󰲸 "
 		}
 
-		isPlaying := !entry.isDir && m.playingIndex != -1 && m.allSongs[m.playingIndex] == entry.path
+		isPlaying := !entry.browsable() && m.playingIndex != -1 && m.playingSong() == entry.path
+
+		badge, columnText := "", ""
+		if !entry.browsable() {
+			badge = m.ratingBadge(entry.path)
+			columnText = m.renderColumns(entry.path, cols)
+		}
 
-		maxWidth := m.width - 14
+		maxWidth := max(m.width-16-lipgloss.Width(badge)-columnsWidth(cols), 3)
 		displayName := entry.name
-		if len(displayName) > maxWidth {
-			displayName = displayName[:maxWidth-3] + "..."
+		matches := entry.matches
+		displayName, matches = fitMatches(displayName, maxWidth, m.nameEllipsis(), matches)
+		suffix := " "
+		if badge != "" {
+			suffix = "  " + badge + " "
+		}
+		if columnText != "" {
+			suffix = padWidth("", maxWidth-lipgloss.Width(displayName)) + suffix
 		}
 
-		var line string
+		var style lipgloss.Style
 		var cursor string
 
 		if i == m.cursor {
@@ -421,21 +662,27 @@ func (m Model) renderBrowser(height int) string {
 		}
 		if i == m.cursor {
 			if isPlaying {
-				line = BrowserItemPlayingSelectedStyle.Render(fmt.Sprintf(" %s %s %s ", cursor, icon, displayName))
-			} else if entry.isDir {
-				line = BrowserItemDirSelectedStyle.Render(fmt.Sprintf(" %s %s %s ", cursor, icon, displayName))
+				style = BrowserItemPlayingSelectedStyle
+			} else if entry.browsable() {
+				style = BrowserItemDirSelectedStyle
 			} else {
-				line = BrowserItemSelectedStyle.Render(fmt.Sprintf(" %s %s %s ", cursor, icon, displayName))
+				style = BrowserItemSelectedStyle
 			}
 		} else {
 			if isPlaying {
-				line = BrowserItemPlayingStyle.Render(fmt.Sprintf(" %s %s %s ", cursor, icon, displayName))
-			} else if entry.isDir {
-				line = BrowserItemDirStyle.Render(fmt.Sprintf(" %s %s %s ", cursor, icon, displayName))
+				style = BrowserItemPlayingStyle
+			} else if entry.browsable() {
+				style = BrowserItemDirStyle
 			} else {
-				line = BrowserItemStyle.Render(fmt.Sprintf(" %s %s %s ", cursor, icon, displayName))
+				style = BrowserItemStyle
 			}
 		}
+		line := style.Render(fmt.Sprintf(" %s %s ", cursor, icon)) +
+			renderMatches(style, displayName, matches) +
+			style.Render(suffix)
+		if columnText != "" {
+			line += BrowserColumnStyle.Render(columnText)
+		}
 		content.WriteString(line + "\n")
 	}
 
@@ -458,16 +705,21 @@ func (m Model) renderPlayerBar() string {
 func (m Model) renderNowPlaying() string {
 	var nowPlaying string
 
+	sleep := ""
+	if label := m.sleepLabel(); label != "" {
+		sleep = NowPlayingLabelStyle.Render("  " + label)
+	}
+
 	if m.loading {
 		icon := NowPlayingIconStyle.Render("◌")
 		text := NowPlayingTextStyle.Render(" Loading...")
 		nowPlaying = "  " + icon + text
 	} else if m.errorMsg != "" {
 		icon := NowPlayingErrorIconStyle.Render("✕")
-		text := NowPlayingErrorTextStyle.Render(" " + m.errorMsg)
+		text := NowPlayingErrorTextStyle.Render(" " + fitWidth(m.errorMsg, m.width-6-lipgloss.Width(sleep), ellipsisEnd))
 		nowPlaying = "  " + icon + text
 	} else if m.playingIndex != -1 {
-		song := filepath.Base(m.allSongs[m.playingIndex])
+		song := filepath.Base(m.playingSong())
 
 		var playIcon string
 		if player.IsPaused() {
@@ -477,7 +729,7 @@ func (m Model) renderNowPlaying() string {
 		}
 
 		label := NowPlayingLabelStyle.Render(" Now Playing: ")
-		songName := NowPlayingSongStyle.Render(song)
+		songName := NowPlayingSongStyle.Render(fitWidth(song, m.width-4-lipgloss.Width(playIcon+label+sleep), m.nameEllipsis()))
 
 		nowPlaying = "  " + playIcon + label + songName
 	} else {
@@ -486,7 +738,7 @@ func (m Model) renderNowPlaying() string {
 		nowPlaying = "  " + icon + text
 	}
 
-	return nowPlaying
+	return nowPlaying + sleep
 }
 
 func (m Model) renderProgressSection() string {
@@ -498,8 +750,11 @@ func (m Model) renderProgressSection() string {
 	currentTime := formatDuration(m.progress, m.total)
 	totalTime := formatDuration(m.total, m.total)
 
-	barWidth := max(m.width-len(currentTime)-len(totalTime)-8, 10)
+	_, barWidth := m.progressBar()
 	bar := renderProgressBar(m.progress, m.total, barWidth)
+	if peaks := m.playingPeaks(); peaks != nil {
+		bar = renderWaveform(peaks, m.progress, m.total, barWidth)
+	}
 
 	timeLeft := PlayerTimeStyle.Render(currentTime)
 	timeRight := PlayerTimeStyle.Render(totalTime)
@@ -508,92 +763,116 @@ func (m Model) renderProgressSection() string {
 	return progressLine
 }
 
-func (m Model) renderControls() string {
-	var controls []string
+// controlButtons are the player controls in the order they are drawn,
+// with spacers that have no action.
+func (m Model) controlButtons() []controlButton {
+	var controls []controlButton
 	showLabels := m.width >= 80
 
+	// With nothing playing, Play starts the selected song.
+	playAction := keymap.Pause
+	if m.playingIndex == -1 {
+		playAction = keymap.Select
+	}
+
 	if m.playingIndex != -1 && !player.IsPaused() {
 		if showLabels {
 			pauseBtn := ControlButtonActiveStyle.Render(" Pause")
-			controls = append(controls, pauseBtn)
+			controls = append(controls, controlButton{keymap.Pause, pauseBtn})
 		} else {
 			pauseBtn := ControlButtonActiveStyle.Render(" ")
-			controls = append(controls, pauseBtn)
+			controls = append(controls, controlButton{keymap.Pause, pauseBtn})
 		}
 	} else {
 		if showLabels {
 			playBtn := ControlButtonStyle.Render("▶ Play")
-			controls = append(controls, playBtn)
+			controls = append(controls, controlButton{playAction, playBtn})
 		} else {
 			playBtn := ControlButtonStyle.Render("▶ ")
-			controls = append(controls, playBtn)
+			controls = append(controls, controlButton{playAction, playBtn})
 		}
 	}
 
-	controls = append(controls, "  ")
+	controls = append(controls, controlButton{text: "  "})
 
 	if showLabels {
 		prevBtn := ControlButtonStyle.Render(" Previous")
-		controls = append(controls, prevBtn)
+		controls = append(controls, controlButton{keymap.Previous, prevBtn})
 	} else {
 		prevBtn := ControlButtonStyle.Render("  ")
-		controls = append(controls, prevBtn)
+		controls = append(controls, controlButton{keymap.Previous, prevBtn})
 	}
 
-	controls = append(controls, "  ")
+	controls = append(controls, controlButton{text: "  "})
 
 	if showLabels {
 		nextBtn := ControlButtonStyle.Render("Next  ")
-		controls = append(controls, nextBtn)
+		controls = append(controls, controlButton{keymap.Next, nextBtn})
 	} else {
 		nextBtn := ControlButtonStyle.Render(" ")
-		controls = append(controls, nextBtn)
+		controls = append(controls, controlButton{keymap.Next, nextBtn})
 	}
 
 	if showLabels {
-		controls = append(controls, "  ")
+		controls = append(controls, controlButton{text: "  "})
 	} else {
-		controls = append(controls, "  ")
+		controls = append(controls, controlButton{text: "  "})
 	}
 
 	if m.repeat {
 		if showLabels {
 			repeatBtn := ControlButtonOnStyle.Render("  Repeat")
-			controls = append(controls, repeatBtn)
+			controls = append(controls, controlButton{keymap.Repeat, repeatBtn})
 		} else {
 			repeatBtn := ControlButtonOnStyle.Render("  ")
-			controls = append(controls, repeatBtn)
+			controls = append(controls, controlButton{keymap.Repeat, repeatBtn})
 		}
 	} else {
 		if showLabels {
 			repeatBtn := ControlButtonOffStyle.Render("  Repeat")
-			controls = append(controls, repeatBtn)
+			controls = append(controls, controlButton{keymap.Repeat, repeatBtn})
 		} else {
 			repeatBtn := ControlButtonOffStyle.Render("  ")
-			controls = append(controls, repeatBtn)
+			controls = append(controls, controlButton{keymap.Repeat, repeatBtn})
 		}
 	}
 
-	controls = append(controls, "  ")
+	controls = append(controls, controlButton{text: "  "})
 
 	if m.shuffle {
 		if showLabels {
 			shuffleBtn := ControlButtonOnStyle.Render("  Shuffle")
-			controls = append(controls, shuffleBtn)
+			controls = append(controls, controlButton{keymap.Shuffle, shuffleBtn})
 		} else {
 			shuffleBtn := ControlButtonOnStyle.Render("  ")
-			controls = append(controls, shuffleBtn)
+			controls = append(controls, controlButton{keymap.Shuffle, shuffleBtn})
 		}
 	} else {
 		if showLabels {
 			shuffleBtn := ControlButtonOffStyle.Render("  Shuffle")
-			controls = append(controls, shuffleBtn)
+			controls = append(controls, controlButton{keymap.Shuffle, shuffleBtn})
 		} else {
 			shuffleBtn := ControlButtonOffStyle.Render("  ")
-			controls = append(controls, shuffleBtn)
+			controls = append(controls, controlButton{keymap.Shuffle, shuffleBtn})
 		}
 	}
 
+	return controls
+}
+
+// progressBar returns the column the progress bar starts at and its width.
+func (m Model) progressBar() (int, int) {
+	currentTime := formatDuration(m.progress, m.total)
+	totalTime := formatDuration(m.total, m.total)
+	return 2 + len(currentTime) + 1, max(m.width-len(currentTime)-len(totalTime)-8, 10)
+}
+
+func (m Model) renderControls() string {
+	var controls []string
+	for _, button := range m.controlButtons() {
+		controls = append(controls, button.text)
+	}
+
 	controlsLine := lipgloss.JoinHorizontal(lipgloss.Left, controls...)
 
 	controlsWidth := lipgloss.Width(controlsLine)
@@ -607,10 +886,21 @@ func saveState(m Model) {
 		CurrentSong: m.playingIndex,
 		Repeat:      m.repeat,
 		Shuffle:     m.shuffle,
+		Sorts:       m.sorts,
+		HideColumns: m.hideColumns,
+		Theme:       m.themeName,
+		Panes:       m.layout.names(),
+		PaneWidth:   m.layout.width,
+		Visualizer:  string(m.visual.style),
+		Waveform:    m.showWaveform,
 	})
 }
 
 func readDir(path string) ([]fsEntry, error) {
+	if playlist.IsPlaylist(path) {
+		return readPlaylist(path)
+	}
+
 	files, err := os.ReadDir(path)
 	if err != nil {
 		return nil, err
@@ -619,59 +909,219 @@ func readDir(path string) ([]fsEntry, error) {
 	var entries []fsEntry
 	for _, file := range files {
 		isDir := file.IsDir()
-		isMusicFile := isSupportedAudioFile(file.Name())
-		if isDir || isMusicFile {
+		isMusicFile := library.IsAudioFile(file.Name())
+		isPlaylist := !isDir && playlist.IsPlaylist(file.Name())
+		if isDir || isMusicFile || isPlaylist {
 			entries = append(entries, fsEntry{
-				name:  file.Name(),
-				path:  filepath.Join(path, file.Name()),
-				isDir: isDir,
+				name:       file.Name(),
+				path:       filepath.Join(path, file.Name()),
+				isDir:      isDir,
+				isPlaylist: isPlaylist,
 			})
 		}
 	}
+	return entries, nil
+}
 
-	sort.SliceStable(entries, func(i, j int) bool {
-		if entries[i].isDir != entries[j].isDir {
-			return entries[i].isDir
-		}
-		return entries[i].name < entries[j].name
-	})
+func readPlaylist(path string) ([]fsEntry, error) {
+	tracks, err := playlist.Load(path)
+	if err != nil {
+		return nil, err
+	}
 
+	entries := make([]fsEntry, 0, len(tracks))
+	for _, track := range tracks {
+		name := track.Title
+		if name == "" {
+			name = filepath.Base(track.Path)
+		}
+		entries = append(entries, fsEntry{name: name, path: track.Path})
+	}
 	return entries, nil
 }
 
+func (m *Model) loadSearchSource() {
+	m.searchBase = ""
+	if !m.searchGlobal {
+		m.searchSource = m.readEntries()
+		return
+	}
+
+	tracks := m.library.Tracks()
+	m.searchSource = make([]fsEntry, 0, len(tracks))
+	for _, track := range tracks {
+		m.searchSource = append(m.searchSource, fsEntry{
+			name: track.Rel,
+			path: track.Path,
+			tags: strings.Join([]string{track.Tags.Title, track.Tags.Artist, track.Tags.Album}, " "),
+		})
+	}
+}
+
 func (m *Model) filterEntries() {
-	allEntries, _ := readDir(m.currentPath)
-	m.entries = []fsEntry{}
-	for _, entry := range allEntries {
-		if strings.Contains(strings.ToLower(entry.name), strings.ToLower(m.searchQuery)) {
-			m.entries = append(m.entries, entry)
+	q, err := query.Parse(m.searchQuery)
+	if err != nil {
+		// Keep the previous results while a filter is still being typed.
+		m.searchErr = "  (" + err.Error() + ")"
+		return
+	}
+	m.searchErr = ""
+
+	// A query that only narrows the previous one can filter the previous
+	// results instead of rescanning the whole source on every keystroke.
+	candidates := m.searchSource
+	if narrows(q, m.searchBase) {
+		candidates = m.entries
+	}
+	now := time.Now()
+
+	type scored struct {
+		entry fsEntry
+		score int
+	}
+	var results []scored
+	for _, entry := range candidates {
+		if len(q.Filters) > 0 && (entry.browsable() || !q.MatchFilters(m.library.Track(entry.path), now)) {
+			continue
 		}
+
+		entry.matches = nil
+		total := 0
+		matched := true
+		for _, term := range q.Terms {
+			if term.Negate {
+				text := strings.ToLower(term.Text)
+				if strings.Contains(strings.ToLower(entry.name), text) || strings.Contains(strings.ToLower(entry.tags), text) {
+					matched = false
+					break
+				}
+				continue
+			}
+			if term.Phrase {
+				if positions, ok := phraseMatch(entry.name, term.Text); ok {
+					total += len(positions) * 16
+					entry.matches = append(entry.matches, positions...)
+				} else if positions, ok := phraseMatch(entry.tags, term.Text); ok {
+					total += len(positions) * 8
+				} else {
+					matched = false
+					break
+				}
+				continue
+			}
+			if r, ok := fuzzy.MatchTerms(term.Text, entry.name); ok {
+				total += r.Score
+				entry.matches = append(entry.matches, r.Positions...)
+			} else if r, ok := fuzzy.MatchTerms(term.Text, entry.tags); ok {
+				total += r.Score / 2
+			} else {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			results = append(results, scored{entry: entry, score: total})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	m.entries = make([]fsEntry, len(results))
+	for i, r := range results {
+		m.entries[i] = r.entry
 	}
+	m.searchBase = m.searchQuery
 	m.cursor = 0
 	m.offset = 0
 }
 
-func loadAllSongs(root string) ([]string, error) {
-	var songs []string
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+// narrows reports whether q can only match what the query base matched:
+// both are plain fuzzy words, and q keeps every word of base in place,
+// perhaps longer, and maybe adds more. Filters such as year:<2 ->
+// year:<20, negated words and phrases can widen as they are typed, and
+// so do words that turn into a "not", so they always rescan.
+func narrows(q query.Query, base string) bool {
+	prev, err := query.Parse(base)
+	if base == "" || err != nil || !plainWords(q) || !plainWords(prev) || len(q.Terms) < len(prev.Terms) {
+		return false
+	}
+	for i, term := range prev.Terms {
+		if !strings.HasPrefix(q.Terms[i].Text, term.Text) {
+			return false
 		}
-		if !d.IsDir() && isSupportedAudioFile(d.Name()) {
-			songs = append(songs, path)
+	}
+	return true
+}
+
+func plainWords(q query.Query) bool {
+	if len(q.Filters) > 0 {
+		return false
+	}
+	for _, term := range q.Terms {
+		if term.Negate || term.Phrase {
+			return false
 		}
-		return nil
-	})
-	return songs, err
+	}
+	return true
 }
 
-func isSupportedAudioFile(name string) bool {
-	lower := strings.ToLower(name)
-	return strings.HasSuffix(lower, ".mp3") ||
-		strings.HasSuffix(lower, ".wav") ||
-		strings.HasSuffix(lower, ".flac") ||
-		strings.HasSuffix(lower, ".ogg") ||
-		strings.HasSuffix(lower, ".oga")
+func phraseMatch(text, phrase string) ([]int, bool) {
+	runes := []rune(strings.ToLower(text))
+	needle := []rune(strings.ToLower(phrase))
+	for start := 0; start+len(needle) <= len(runes); start++ {
+		if string(runes[start:start+len(needle)]) == string(needle) {
+			positions := make([]int, len(needle))
+			for i := range needle {
+				positions[i] = start + i
+			}
+			return positions, true
+		}
+	}
+	return nil, false
+}
+
+func renderMatches(style lipgloss.Style, text string, matches []int) string {
+	if len(matches) == 0 {
+		return style.Render(text)
+	}
+
+	matchStyle := style.Foreground(colorMagenta).Underline(true)
+	matched := map[int]bool{}
+	for _, pos := range matches {
+		matched[pos] = true
+	}
+
+	var out strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			out.WriteString(matchStyle.Render(string(run)))
+		} else {
+			out.WriteString(style.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return out.String()
+}
+
+func (m Model) searchLabel() string {
+	if m.searchGlobal {
+		return "Search library"
+	}
+	return "Search folder"
 }
 
 func findSongIndex(songs []string, path string) int {
@@ -727,41 +1177,3 @@ func renderProgressBar(current, total float64, width int) string {
 
 	return bar
 }
-
-var (
-	HeaderBoxStyle   = lipgloss.NewStyle().Background(everblushBg1).BorderStyle(lipgloss.RoundedBorder()).BorderForeground(everblushGray).BorderBottom(true).Padding(0, 1)
-	HeaderTitleStyle = lipgloss.NewStyle().Foreground(everblushYellow).Bold(true)
-	HeaderInfoStyle  = lipgloss.NewStyle().Italic(true)
-)
-
-var (
-	BrowserBoxStyle                 = lipgloss.NewStyle().Background(everblushBg0).Padding(0)
-	BrowserPathStyle                = lipgloss.NewStyle().Foreground(everblushBlue).Bold(true).Background(everblushBg1)
-	BrowserSeparatorStyle           = lipgloss.NewStyle().Foreground(everblushGray)
-	BrowserItemStyle                = lipgloss.NewStyle().Foreground(everblushFg)
-	BrowserItemDirStyle             = lipgloss.NewStyle().Foreground(everblushCyan).Bold(true)
-	BrowserItemSelectedStyle        = lipgloss.NewStyle().Foreground(everblushYellow).Background(everblushBg1).Bold(true)
-	BrowserItemDirSelectedStyle     = lipgloss.NewStyle().Foreground(everblushCyan).Background(everblushBg1).Bold(true)
-	BrowserItemPlayingStyle         = lipgloss.NewStyle().Foreground(everblushGreen).Bold(true)
-	BrowserItemPlayingSelectedStyle = lipgloss.NewStyle().Foreground(everblushGreen).Background(everblushBg1).Bold(true)
-)
-
-var (
-	PlayerBorderStyle            = lipgloss.NewStyle().Foreground(everblushGray)
-	NowPlayingIconStyle          = lipgloss.NewStyle().Foreground(everblushMagenta).Bold(true)
-	NowPlayingLabelStyle         = lipgloss.NewStyle().Foreground(everblushFg)
-	NowPlayingSongStyle          = lipgloss.NewStyle().Foreground(everblushYellow).Bold(true)
-	NowPlayingTextStyle          = lipgloss.NewStyle().Foreground(everblushFg)
-	NowPlayingIdleIconStyle      = lipgloss.NewStyle().Foreground(everblushGray)
-	NowPlayingIdleTextStyle      = lipgloss.NewStyle().Foreground(everblushGray).Italic(true)
-	NowPlayingErrorIconStyle     = lipgloss.NewStyle().Foreground(everblushRed).Bold(true)
-	NowPlayingErrorTextStyle     = lipgloss.NewStyle().Foreground(everblushRed)
-	PlayerTimeStyle              = lipgloss.NewStyle().Foreground(everblushGray)
-	PlayerProgressFilledStyle    = lipgloss.NewStyle().Foreground(everblushGreen).Bold(true)
-	PlayerProgressIndicatorStyle = lipgloss.NewStyle().Foreground(everblushYellow).Bold(true)
-	PlayerProgressEmptyStyle     = lipgloss.NewStyle().Foreground(everblushGray)
-	ControlButtonStyle           = lipgloss.NewStyle().Foreground(everblushFg)
-	ControlButtonActiveStyle     = lipgloss.NewStyle().Foreground(everblushBlue).Bold(true)
-	ControlButtonOnStyle         = lipgloss.NewStyle().Foreground(everblushGreen).Bold(true)
-	ControlButtonOffStyle        = lipgloss.NewStyle().Foreground(everblushGray)
-)
@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const doubleClickTime = 400 * time.Millisecond
+
+type lastClick struct {
+	at     time.Time
+	y      int
+	column int
+}
+
+func (m *Model) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.promptMode || m.command.open || msg.Action != tea.MouseActionPress {
+		return nil
+	}
+
+	column := m.columnAt(msg.X)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scroll(column, -1)
+	case tea.MouseButtonWheelDown:
+		m.scroll(column, 1)
+	case tea.MouseButtonLeft:
+		listTop := lipgloss.Height(m.renderHeader()) + 2
+		playerTop := listTop - 2 + m.browserHeight()
+		// Lists draw one row fewer than the space above the player bar.
+		listRows := max(m.browserHeight()-3, 1)
+
+		double := msg.Y == m.click.y && column == m.click.column && time.Since(m.click.at) < doubleClickTime
+		m.click = lastClick{at: time.Now(), y: msg.Y, column: column}
+		if double {
+			// A third click starts a new double-click.
+			m.click = lastClick{}
+		}
+
+		switch {
+		case msg.Y == playerTop+2:
+			m.seekTo(msg.X)
+		case msg.Y == playerTop+3:
+			if action := m.controlAt(msg.X); action != "" {
+				return m.browserAction(action)
+			}
+		case msg.Y >= listTop && msg.Y < listTop+listRows:
+			m.layout.focus = column
+			if p := m.focusedPane(); p != nil {
+				m.clickSide(p, msg.Y-listTop, double)
+				return nil
+			}
+			return m.clickRow(msg.Y-listTop, double)
+		}
+	}
+	return nil
+}
+
+// scroll moves the cursor of the list in the given column.
+func (m *Model) scroll(column, delta int) {
+	visible := m.height - 16
+	if column > 0 {
+		p := &m.layout.panes[column-1]
+		switch p.kind {
+		case queuePane:
+			moveCursor(&p.cursor, &p.offset, delta, len(m.queue), visible)
+		case infoPane:
+			m.scrollInfo(p, delta)
+		case lyricsPane:
+			m.scrollLyrics(p, delta)
+		}
+		return
+	}
+
+	switch {
+	case m.help.open:
+		maxOffset := max(len(m.helpLines())-max(m.height-9, 1), 0)
+		m.help.offset = min(max(m.help.offset+delta, 0), maxOffset)
+	case m.picker.open:
+		moveCursor(&m.picker.cursor, &m.picker.offset, delta, len(m.pickerNames()), visible)
+	case m.pane.open && !m.searchMode:
+		moveCursor(&m.pane.cursor, &m.pane.offset, delta, m.paneRowCount(), visible)
+	default:
+		m.moveBrowserCursor(delta)
+	}
+}
+
+// clickRow selects the list row under the pointer, row being counted from
+// the first visible one. A double-click also opens or plays it.
+func (m *Model) clickRow(row int, double bool) tea.Cmd {
+	// The offset is brought into the window the keys scroll, so the list
+	// does not jump on the next key press.
+	visible := m.height - 16
+	switch {
+	case m.help.open:
+	case m.picker.open:
+		if index := m.picker.offset + row; index < len(m.pickerNames()) {
+			m.picker.cursor = index
+			moveCursor(&m.picker.cursor, &m.picker.offset, 0, len(m.pickerNames()), visible)
+			if double {
+				m.pickerAction(keymap.Select)
+			}
+		}
+	case m.pane.open && !m.searchMode:
+		if index := m.pane.offset + row; index < m.paneRowCount() {
+			m.pane.cursor = index
+			moveCursor(&m.pane.cursor, &m.pane.offset, 0, m.paneRowCount(), visible)
+			if double {
+				m.paneAction(keymap.Select)
+			}
+		}
+	default:
+		if index := m.offset + row; index < len(m.entries) {
+			m.cursor = index
+			m.moveBrowserCursor(0)
+			if !double {
+				break
+			}
+			if m.searchMode {
+				m.searchAction(keymap.Select)
+			} else {
+				return m.browserAction(keymap.Select)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Model) clickSide(p *sidePane, row int, double bool) {
+	switch p.kind {
+	case queuePane:
+		if index := p.offset + row; index < len(m.queue) {
+			p.cursor = index
+			moveCursor(&p.cursor, &p.offset, 0, len(m.queue), m.height-16)
+			if double {
+				m.queueAction(p, keymap.Select)
+			}
+		}
+	case lyricsPane:
+		if double {
+			m.clickLyrics(p, row)
+		}
+	case visualizerPane:
+		m.cycleVisualStyle()
+	}
+}
+
+func (m *Model) seekTo(x int) {
+	if m.playingIndex == -1 || m.loading {
+		return
+	}
+	start, width := m.progressBar()
+	if x < start || x >= start+width {
+		return
+	}
+	fraction := (float64(x-start) + 0.5) / float64(width)
+	if err := m.seek(fraction); err != nil {
+		m.errorMsg = "Seek failed: " + err.Error()
+	}
+}
+
+// seek jumps to the given fraction of the playing song.
+func (m *Model) seek(fraction float64) error {
+	fraction = min(max(fraction, 0), 1)
+	if err := player.Seek(fraction); err != nil {
+		return err
+	}
+	m.progress = fraction * m.total
+	return nil
+}
+
+// controlAt returns the action of the control button at column x, laid out
+// the same way renderControls centers them.
+func (m Model) controlAt(x int) keymap.Action {
+	buttons := m.controlButtons()
+	width := 0
+	for _, button := range buttons {
+		width += lipgloss.Width(button.text)
+	}
+
+	left := max((m.width-width)/2, 2)
+	for _, button := range buttons {
+		right := left + lipgloss.Width(button.text)
+		if x >= left && x < right {
+			return button.action
+		}
+		left = right
+	}
+	return ""
+}
@@ -0,0 +1,423 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/library"
+	"github.com/Gylmynnn/dicesong/playlist"
+	"github.com/Gylmynnn/dicesong/smart"
+	"github.com/charmbracelet/bubbletea"
+)
+
+const newPlaylistLabel = "+ New playlist"
+
+type playlistPane struct {
+	open    bool
+	current string
+	smart   bool
+	cursor  int
+	offset  int
+}
+
+type paneItem struct {
+	name  string
+	smart bool
+}
+
+type playlistPicker struct {
+	open   bool
+	tracks []string
+	cursor int
+	offset int
+}
+
+func moveCursor(cursor, offset *int, delta, count, visible int) {
+	visible = max(visible, 1)
+	*cursor = min(max(*cursor+delta, 0), max(count-1, 0))
+	if *cursor < *offset {
+		*offset = *cursor
+	}
+	if *cursor >= *offset+visible {
+		*offset = *cursor - visible + 1
+	}
+}
+
+func (m Model) paneItems() []paneItem {
+	var items []paneItem
+	for _, name := range m.playlists.Names() {
+		items = append(items, paneItem{name: name})
+	}
+	for _, name := range m.smartLists.Names() {
+		items = append(items, paneItem{name: name, smart: true})
+	}
+	return items
+}
+
+func (m Model) paneTracks() []string {
+	if m.pane.smart {
+		return m.smartTracks[m.pane.current]
+	}
+	return m.playlists.Tracks(m.pane.current)
+}
+
+func (m Model) paneRowCount() int {
+	if m.pane.current == "" {
+		return len(m.paneItems())
+	}
+	return len(m.paneTracks())
+}
+
+func (m *Model) togglePlaylistPane() {
+	m.pane.open = !m.pane.open
+	m.pane.cursor = min(m.pane.cursor, max(m.paneRowCount()-1, 0))
+	m.pane.offset = min(m.pane.offset, m.pane.cursor)
+}
+
+func (m *Model) refreshSmart(name string) {
+	tracks, err := smart.Evaluate(m.library, m.smartLists.Rules(name))
+	if err != nil {
+		m.errorMsg = "Smart playlist failed: " + err.Error()
+		return
+	}
+	m.smartTracks[name] = tracks
+}
+
+func (m *Model) updatePlaylistPane(msg tea.KeyMsg) bool {
+	if m.paneAction(m.Keys.Action(keymap.ScopePane, msg.String())) {
+		return true
+	}
+	// Adding to a playlist and searching act on the browser, which the
+	// pane hides; everything else falls through to it.
+	switch m.Keys.Action(keymap.ScopeBrowser, msg.String()) {
+	case keymap.AddToPlaylist, keymap.Search:
+		return true
+	}
+	return false
+}
+
+// paneAction runs a playlist pane action and reports whether there was one.
+func (m *Model) paneAction(action keymap.Action) bool {
+	items := m.paneItems()
+	tracks := m.paneTracks()
+	count := m.paneRowCount()
+	visible := m.height - 16
+
+	var selected paneItem
+	if m.pane.current == "" && m.pane.cursor < len(items) {
+		selected = items[m.pane.cursor]
+	}
+
+	switch action {
+	case keymap.Cancel:
+		m.pane.open = false
+	case keymap.Up:
+		moveCursor(&m.pane.cursor, &m.pane.offset, -1, count, visible)
+	case keymap.Down:
+		moveCursor(&m.pane.cursor, &m.pane.offset, 1, count, visible)
+	case keymap.Select, keymap.Open:
+		if count == 0 {
+			break
+		}
+		if m.pane.current == "" {
+			m.pane.current, m.pane.smart = selected.name, selected.smart
+			m.pane.cursor, m.pane.offset = 0, 0
+			if selected.smart {
+				if _, ok := m.smartTracks[selected.name]; !ok {
+					m.refreshSmart(selected.name)
+				}
+			}
+			break
+		}
+		if m.loading || time.Since(m.lastPlay) < 300*time.Millisecond {
+			break
+		}
+		m.playQueue(tracks, m.pane.cursor)
+	case keymap.Back:
+		if m.pane.current != "" {
+			current := paneItem{name: m.pane.current, smart: m.pane.smart}
+			m.pane.current, m.pane.smart = "", false
+			m.pane.cursor = max(slices.Index(m.paneItems(), current), 0)
+			m.pane.offset = 0
+			moveCursor(&m.pane.cursor, &m.pane.offset, 0, m.paneRowCount(), visible)
+		}
+	case keymap.SmartRefresh:
+		switch {
+		case m.pane.smart:
+			m.refreshSmart(m.pane.current)
+			moveCursor(&m.pane.cursor, &m.pane.offset, 0, m.paneRowCount(), visible)
+		case selected.smart:
+			m.refreshSmart(selected.name)
+		}
+	case keymap.PlaylistCreate:
+		m.startPrompt("New playlist", "", func(m *Model, name string) error {
+			return m.playlists.Create(name)
+		})
+	case keymap.SmartCreate:
+		m.startPrompt("New smart playlist", "", func(m *Model, name string) error {
+			m.startPrompt("Rules for "+name, "", func(m *Model, rules string) error {
+				if err := m.smartLists.Create(name, rules); err != nil {
+					return err
+				}
+				m.refreshSmart(name)
+				return nil
+			})
+			return nil
+		})
+	case keymap.SmartEdit:
+		name := m.pane.current
+		if !m.pane.smart {
+			name = selected.name
+			if !selected.smart {
+				break
+			}
+		}
+		m.startPrompt("Rules for "+name, m.smartLists.Rules(name), func(m *Model, rules string) error {
+			if err := m.smartLists.SetRules(name, rules); err != nil {
+				return err
+			}
+			m.refreshSmart(name)
+			return nil
+		})
+	case keymap.PlaylistRename:
+		if m.pane.current != "" || selected.name == "" {
+			break
+		}
+		m.startPrompt("Rename playlist", selected.name, func(m *Model, newName string) error {
+			if !selected.smart {
+				return m.playlists.Rename(selected.name, newName)
+			}
+			if err := m.smartLists.Rename(selected.name, newName); err != nil {
+				return err
+			}
+			delete(m.smartTracks, selected.name)
+			return nil
+		})
+	case keymap.PlaylistDelete:
+		if m.pane.current != "" || selected.name == "" {
+			break
+		}
+		var err error
+		if selected.smart {
+			err = m.smartLists.Delete(selected.name)
+			delete(m.smartTracks, selected.name)
+		} else {
+			err = m.playlists.Delete(selected.name)
+		}
+		if err != nil {
+			m.errorMsg = "Delete failed: " + err.Error()
+		}
+		moveCursor(&m.pane.cursor, &m.pane.offset, 0, count-1, visible)
+	case keymap.PlaylistRemove:
+		if m.pane.current == "" || m.pane.smart || count == 0 {
+			break
+		}
+		if err := m.playlists.Remove(m.pane.current, m.pane.cursor); err != nil {
+			m.errorMsg = "Remove failed: " + err.Error()
+		}
+		moveCursor(&m.pane.cursor, &m.pane.offset, 0, count-1, visible)
+	case keymap.PlaylistMoveUp, keymap.PlaylistMoveDown:
+		if m.pane.current == "" || m.pane.smart || count < 2 {
+			break
+		}
+		delta := -1
+		if action == keymap.PlaylistMoveDown {
+			delta = 1
+		}
+		to := m.pane.cursor + delta
+		if to < 0 || to >= count {
+			break
+		}
+		if err := m.playlists.Move(m.pane.current, m.pane.cursor, to); err != nil {
+			m.errorMsg = "Move failed: " + err.Error()
+			break
+		}
+		moveCursor(&m.pane.cursor, &m.pane.offset, delta, count, visible)
+	case keymap.PlaylistExport:
+		item := paneItem{name: m.pane.current, smart: m.pane.smart}
+		if item.name == "" {
+			item = selected
+		}
+		if item.name == "" {
+			break
+		}
+		m.startPrompt("Export playlist", exportName(item.name), func(m *Model, file string) error {
+			var err error
+			if item.smart {
+				if _, ok := m.smartTracks[item.name]; !ok {
+					m.refreshSmart(item.name)
+				}
+				err = m.savePlaylist(file, m.smartTracks[item.name])
+			} else {
+				err = m.playlists.Export(item.name, m.playlistPath(file))
+			}
+			if err != nil {
+				return err
+			}
+			m.entries = m.readEntries()
+			return nil
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *Model) playQueue(queue []string, index int) {
+	m.lastPlay = time.Now()
+	m.loading = true
+	m.queue = queue
+	m.playingIndex = index
+	m.PlayRequest <- queue[index]
+	saveState(*m)
+}
+
+func (m *Model) openPicker(entry fsEntry) {
+	tracks := m.entryTracks(entry)
+	if len(tracks) == 0 {
+		return
+	}
+	m.picker = playlistPicker{open: true, tracks: tracks}
+}
+
+// entryTracks returns the songs in a browser entry.
+func (m Model) entryTracks(entry fsEntry) []string {
+	var tracks []string
+	switch {
+	case entry.isDir && m.view != filesView:
+		tracks = m.groupSongs(entry.path)
+	case entry.isDir:
+		tracks, _ = library.Scan(entry.path)
+	case entry.isPlaylist:
+		if list, err := playlist.Load(entry.path); err == nil {
+			tracks = playlist.Paths(list)
+		}
+	default:
+		tracks = []string{entry.path}
+	}
+	return tracks
+}
+
+func (m *Model) updatePicker(msg tea.KeyMsg) {
+	m.pickerAction(m.Keys.Action(keymap.ScopeBrowser, msg.String()))
+}
+
+func (m *Model) pickerAction(action keymap.Action) {
+	names := m.pickerNames()
+
+	switch action {
+	case keymap.Cancel, keymap.Quit:
+		m.picker.open = false
+	case keymap.Up:
+		moveCursor(&m.picker.cursor, &m.picker.offset, -1, len(names), m.height-16)
+	case keymap.Down:
+		moveCursor(&m.picker.cursor, &m.picker.offset, 1, len(names), m.height-16)
+	case keymap.Select:
+		m.picker.open = false
+		tracks := m.picker.tracks
+		if m.picker.cursor == 0 {
+			m.startPrompt("New playlist", "", func(m *Model, name string) error {
+				if err := m.playlists.Create(name); err != nil {
+					return err
+				}
+				return m.playlists.Add(strings.TrimSpace(name), tracks)
+			})
+			break
+		}
+		if err := m.playlists.Add(names[m.picker.cursor], tracks); err != nil {
+			m.errorMsg = "Add failed: " + err.Error()
+		}
+	}
+}
+
+func (m Model) pickerNames() []string {
+	return append([]string{newPlaylistLabel}, m.playlists.Names()...)
+}
+
+func exportName(name string) string {
+	return strings.NewReplacer("/", "-", "\\", "-").Replace(name) + ".m3u8"
+}
+
+func (m Model) renderPlaylists(height int) string {
+	title := "Playlists"
+	var rows []string
+	var playing []bool
+	if m.pane.current == "" {
+		for _, item := range m.paneItems() {
+			if item.smart {
+				rows = append(rows, fmt.Sprintf("%s [smart: %s]", item.name, m.smartLists.Rules(item.name)))
+			} else {
+				rows = append(rows, fmt.Sprintf("%s (%d)", item.name, len(m.playlists.Tracks(item.name))))
+			}
+			playing = append(playing, false)
+		}
+	} else {
+		title += " / " + m.pane.current
+		if m.pane.smart {
+			title += " [" + m.smartLists.Rules(m.pane.current) + "]"
+		}
+		song := m.playingSong()
+		for _, track := range m.paneTracks() {
+			rows = append(rows, filepath.Base(track))
+			playing = append(playing, track == song)
+		}
+	}
+
+	icon := "\uf001 "
+	if m.pane.current == "" {
+		icon = "\U000f0cb8 "
+	}
+	return m.renderList(height, "\U000f0cb8 "+title, rows, icon, m.pane.current == "", playing, m.pane.cursor, m.pane.offset)
+}
+
+func (m Model) renderPicker(height int) string {
+	names := m.pickerNames()
+	title := fmt.Sprintf("\U000f0cb8 Add %d song(s) to playlist", len(m.picker.tracks))
+	return m.renderList(height, title, names, "", true, make([]bool, len(names)), m.picker.cursor, m.picker.offset)
+}
+
+func (m Model) renderList(height int, title string, rows []string, icon string, dirStyle bool, playing []bool, cursor, offset int) string {
+	var content strings.Builder
+
+	content.WriteString(m.titleStyle().Render("  "+title+"  ") + "\n")
+	content.WriteString(BrowserSeparatorStyle.Render(strings.Repeat("─", m.width)) + "\n")
+
+	visibleRows := max(height-3, 1)
+	end := min(offset+visibleRows, len(rows))
+
+	for i := offset; i < end; i++ {
+		name := fitWidth(rows[i], max(m.width-16, 3), m.nameEllipsis())
+
+		marker := " "
+		if i == cursor {
+			marker = "▶"
+		}
+		text := fmt.Sprintf(" %s %s%s ", marker, icon, name)
+
+		var line string
+		switch {
+		case i == cursor && playing[i]:
+			line = BrowserItemPlayingSelectedStyle.Render(text)
+		case i == cursor && dirStyle:
+			line = BrowserItemDirSelectedStyle.Render(text)
+		case i == cursor:
+			line = BrowserItemSelectedStyle.Render(text)
+		case playing[i]:
+			line = BrowserItemPlayingStyle.Render(text)
+		case dirStyle:
+			line = BrowserItemDirStyle.Render(text)
+		default:
+			line = BrowserItemStyle.Render(text)
+		}
+		content.WriteString(line + "\n")
+	}
+
+	return BrowserBoxStyle.
+		Width(m.width).
+		Height(height).
+		Render(content.String())
+}
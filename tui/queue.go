@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/playlist"
+	"github.com/charmbracelet/bubbletea"
+)
+
+func (m *Model) playEntry(entry fsEntry) {
+	m.lastPlay = time.Now()
+	m.loading = true
+
+	m.queue = m.contextQueue()
+	m.playingIndex = findSongIndex(m.queue, entry.path)
+	if m.playingIndex == -1 {
+		m.queue = []string{entry.path}
+		m.playingIndex = 0
+	}
+
+	m.PlayRequest <- entry.path
+	saveState(*m)
+}
+
+// enqueue schedules path to play right after the current song.
+func (m *Model) enqueue(path string) {
+	queue := slices.Clone(m.queue)
+	if m.playingIndex < 0 {
+		m.queue = append([]string{path}, queue...)
+		return
+	}
+	m.queue = slices.Insert(queue, m.playingIndex+1, path)
+}
+
+// queueAction runs an action in the queue pane. Open and Back are taken
+// so they do not move the browser behind it.
+func (m *Model) queueAction(p *sidePane, action keymap.Action) bool {
+	visible := m.height - 16
+	i := p.cursor
+
+	switch action {
+	case keymap.Up:
+		moveCursor(&p.cursor, &p.offset, -1, len(m.queue), visible)
+	case keymap.Down:
+		moveCursor(&p.cursor, &p.offset, 1, len(m.queue), visible)
+	case keymap.Select, keymap.Open:
+		if i >= len(m.queue) || m.loading || time.Since(m.lastPlay) < 300*time.Millisecond {
+			break
+		}
+		m.playQueue(m.queue, i)
+	case keymap.Back:
+	case keymap.PlaylistRemove:
+		// The playing song stays so Next and Previous keep their place.
+		if i >= len(m.queue) || i == m.playingIndex {
+			break
+		}
+		m.queue = slices.Delete(slices.Clone(m.queue), i, i+1)
+		if i < m.playingIndex {
+			m.playingIndex--
+		}
+		moveCursor(&p.cursor, &p.offset, 0, len(m.queue), visible)
+	case keymap.PlaylistMoveUp, keymap.PlaylistMoveDown:
+		j := i - 1
+		if action == keymap.PlaylistMoveDown {
+			j = i + 1
+		}
+		if i >= len(m.queue) || j < 0 || j >= len(m.queue) {
+			break
+		}
+		m.queue = slices.Clone(m.queue)
+		m.queue[i], m.queue[j] = m.queue[j], m.queue[i]
+		switch m.playingIndex {
+		case i:
+			m.playingIndex = j
+		case j:
+			m.playingIndex = i
+		}
+		moveCursor(&p.cursor, &p.offset, j-i, len(m.queue), visible)
+	default:
+		return false
+	}
+	return true
+}
+
+func (m Model) renderQueue(p sidePane, height int) string {
+	rows := make([]string, len(m.queue))
+	playing := make([]bool, len(m.queue))
+	for i, track := range m.queue {
+		rows[i] = filepath.Base(track)
+		playing[i] = i == m.playingIndex
+	}
+	title := fmt.Sprintf("\uf0cb Queue (%d)", len(m.queue))
+	return m.renderList(height, title, rows, "\uf001 ", false, playing, p.cursor, p.offset)
+}
+
+func (m Model) entrySongs() []string {
+	var songs []string
+	for _, entry := range m.entries {
+		if !entry.browsable() {
+			songs = append(songs, entry.path)
+		}
+	}
+	return songs
+}
+
+func (m *Model) startSavePrompt(tracks []string) {
+	if len(tracks) == 0 {
+		return
+	}
+	m.startPrompt("Save playlist", "", func(m *Model, name string) error {
+		return m.saveTracks(name, tracks)
+	})
+}
+
+// saveTracks saves tracks as a playlist file and shows it in the browser.
+func (m *Model) saveTracks(name string, tracks []string) error {
+	if err := m.savePlaylist(name, tracks); err != nil {
+		return err
+	}
+	if !m.searchMode {
+		m.entries = m.readEntries()
+	}
+	return nil
+}
+
+func (m *Model) startPrompt(label, input string, submit func(*Model, string) error) {
+	m.promptMode = true
+	m.promptLabel = label
+	m.promptInput = input
+	m.promptSubmit = submit
+}
+
+func (m *Model) updatePrompt(msg tea.KeyMsg) {
+	if msg.Type == tea.KeyBackspace {
+		if runes := []rune(m.promptInput); len(runes) > 0 {
+			m.promptInput = string(runes[:len(runes)-1])
+		}
+		return
+	}
+
+	switch m.Keys.Action(keymap.ScopeSearch, msg.String()) {
+	case keymap.Cancel:
+		m.promptMode = false
+	case keymap.Select:
+		m.promptMode = false
+		name := strings.TrimSpace(m.promptInput)
+		if name == "" {
+			return
+		}
+		if err := m.promptSubmit(m, name); err != nil {
+			m.errorMsg = m.promptLabel + " failed: " + err.Error()
+		}
+	default:
+		if len(msg.Runes) > 0 {
+			m.promptInput += string(msg.Runes)
+		}
+	}
+}
+
+func (m Model) savePlaylist(name string, tracks []string) error {
+	return playlist.Save(m.playlistPath(name), playlist.FromPaths(tracks))
+}
+
+func (m Model) playlistPath(name string) string {
+	dir := m.currentPath
+	if playlist.IsPlaylist(dir) {
+		dir = filepath.Dir(dir)
+	}
+	if !playlist.IsPlaylist(name) {
+		name += ".m3u8"
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+
+	"github.com/Gylmynnn/dicesong/ratings"
+	"github.com/Gylmynnn/dicesong/tags"
+	"github.com/charmbracelet/bubbletea"
+)
+
+type tagWrittenMsg struct {
+	path string
+	err  error
+}
+
+// tagWriter writes rating tags one file at a time, since each write reads
+// and rewrites the tag block. Only the latest rating of a file is
+// written; commands that find it already written do nothing.
+type tagWriter struct {
+	writing sync.Mutex
+	mutex   sync.Mutex
+	pending map[string]int
+}
+
+func (w *tagWriter) write(path string, rating int) tea.Cmd {
+	w.mutex.Lock()
+	w.pending[path] = rating
+	w.mutex.Unlock()
+
+	return func() tea.Msg {
+		w.writing.Lock()
+		defer w.writing.Unlock()
+
+		w.mutex.Lock()
+		rating, ok := w.pending[path]
+		delete(w.pending, path)
+		w.mutex.Unlock()
+		if !ok {
+			return nil
+		}
+		return tagWrittenMsg{path: path, err: tags.WriteRating(path, rating)}
+	}
+}
+
+func (m Model) selectedSong() string {
+	if m.pane.open {
+		if tracks := m.paneTracks(); m.pane.current != "" && m.pane.cursor < len(tracks) {
+			return tracks[m.pane.cursor]
+		}
+		return ""
+	}
+	if m.cursor < 0 || m.cursor >= len(m.entries) || m.entries[m.cursor].isDir {
+		return ""
+	}
+	return m.entries[m.cursor].path
+}
+
+func (m Model) playingSong() string {
+	if m.playingIndex < 0 || m.playingIndex >= len(m.queue) {
+		return ""
+	}
+	return m.queue[m.playingIndex]
+}
+
+func (m *Model) rateSong(path string, rating int) tea.Cmd {
+	if path == "" {
+		return nil
+	}
+	entry, err := m.ratings.SetRating(path, rating)
+	if err != nil {
+		m.errorMsg = "Rating failed: " + err.Error()
+		return nil
+	}
+	if !m.WriteRatingTags {
+		return nil
+	}
+	return m.tagWriter.write(path, entry.Rating)
+}
+
+func (m *Model) toggleFavorite(path string) {
+	if path == "" {
+		return
+	}
+	if _, err := m.ratings.ToggleFavorite(path); err != nil {
+		m.errorMsg = "Favorite failed: " + err.Error()
+	}
+}
+
+func (m Model) ratingBadge(path string) string {
+	entry := m.ratings.Get(path)
+	badge := ""
+	if entry.Favorite {
+		badge = "♥ "
+	}
+	if entry.Rating > 0 {
+		badge += ratings.Stars(entry.Rating)
+	}
+	return badge
+}
+
+func (m *Model) handleTagWritten(msg tagWrittenMsg) {
+	switch {
+	case errors.Is(msg.err, tags.ErrRatingUnsupported):
+		m.errorMsg = "Rating saved, but not to the file: " + msg.err.Error()
+	case msg.err != nil:
+		m.errorMsg = "Tag write failed (" + filepath.Base(msg.path) + "): " + msg.err.Error()
+	}
+}
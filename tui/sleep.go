@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gylmynnn/dicesong/notifier"
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// DefaultSleepFade is how long the sleep timer fades out for unless
+	// --sleep-fade or the sleep command say otherwise.
+	DefaultSleepFade = 30 * time.Second
+
+	// sleepMargin is how close to the end of the last track the timer
+	// fires, so the next track never starts between two ticks.
+	sleepMargin = 300 * time.Millisecond
+
+	// fadeRestore is how fast the volume comes back after a fade-out was
+	// cancelled or ended in a pause.
+	fadeRestore = 500 * time.Millisecond
+)
+
+// sleepTimer pauses or quits at a deadline or at the end of a number of
+// tracks, fading out for the last fade of it.
+type sleepTimer struct {
+	deadline time.Time
+	// tracks counts the songs left to finish, the playing one included.
+	tracks int
+	fade   time.Duration
+	quit   bool
+	fading bool
+}
+
+func (s sleepTimer) active() bool {
+	return !s.deadline.IsZero() || s.tracks > 0
+}
+
+// sleepCommand sets the timer from "30m", "1:30:00", "45" (minutes),
+// "track" or "3 tracks", optionally followed by "quit" or "pause" and
+// "fade 1m", or cancels it with "off".
+func (m *Model) sleepCommand(arg string) (tea.Cmd, error) {
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		return nil, errUsage
+	}
+	if fields[0] == "off" {
+		m.cancelSleep()
+		return nil, nil
+	}
+
+	timer := sleepTimer{fade: m.SleepFade}
+	rest := fields[1:]
+	if n, err := strconv.Atoi(fields[0]); err == nil && len(rest) > 0 && (rest[0] == "tracks" || rest[0] == "track") {
+		if n < 1 {
+			return nil, fmt.Errorf("invalid track count %d", n)
+		}
+		timer.tracks = n
+		rest = rest[1:]
+	} else if fields[0] == "track" {
+		timer.tracks = 1
+	} else {
+		d, err := parseSleepTime(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("sleep time %s is not positive", fields[0])
+		}
+		timer.deadline = time.Now().Add(d)
+	}
+	if timer.tracks > 0 && m.playingIndex == -1 {
+		return nil, errors.New("nothing is playing")
+	}
+	if timer.tracks > 0 && m.loading {
+		// The song being loaded is the current one, and counts once it starts.
+		timer.tracks++
+	}
+
+	for len(rest) > 0 {
+		switch rest[0] {
+		case "quit":
+			timer.quit = true
+		case "pause":
+			timer.quit = false
+		case "fade":
+			if len(rest) < 2 {
+				return nil, errUsage
+			}
+			d, err := parseClock(rest[1])
+			if err != nil {
+				return nil, err
+			}
+			if d < 0 {
+				return nil, fmt.Errorf("fade %s is negative", rest[1])
+			}
+			timer.fade = d
+			rest = rest[1:]
+		default:
+			return nil, errUsage
+		}
+		rest = rest[1:]
+	}
+
+	m.cancelSleep()
+	m.sleep = timer
+	return nil, nil
+}
+
+// parseSleepTime reads a sleep time, where a bare number is minutes.
+func parseSleepTime(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Minute, nil
+	}
+	return parseClock(s)
+}
+
+func (m *Model) cancelSleep() {
+	if m.sleep.fading {
+		player.FadeTo(1, fadeRestore)
+	}
+	m.sleep = sleepTimer{}
+}
+
+// sleepRemaining is the time until the timer fires. In track mode it is
+// only known once the last track is playing.
+func (m Model) sleepRemaining() (time.Duration, bool) {
+	switch {
+	case !m.sleep.deadline.IsZero():
+		return time.Until(m.sleep.deadline), true
+	case m.sleep.tracks == 1 && m.playingIndex != -1 && !m.loading:
+		format, ok := player.CurrentFormat()
+		if !ok {
+			return 0, false
+		}
+		return format.SampleRate.D(int(m.total - m.progress)), true
+	}
+	return 0, false
+}
+
+// sleepLoaded counts a newly started song against the timer. A song that
+// starts after the last one should have ended means the timer missed the
+// end, so it fires at once.
+func (m *Model) sleepLoaded() tea.Cmd {
+	if m.sleep.tracks == 0 {
+		return nil
+	}
+	m.sleep.tracks--
+	if m.sleep.tracks == 0 {
+		return m.fireSleep()
+	}
+	return nil
+}
+
+// updateSleep starts the fade-out and fires the timer, on every tick.
+func (m *Model) updateSleep() tea.Cmd {
+	remaining, ok := m.sleepRemaining()
+	if !ok {
+		return nil
+	}
+	if remaining <= sleepMargin {
+		return m.fireSleep()
+	}
+
+	if remaining <= m.sleep.fade && !m.sleep.fading {
+		player.FadeTo(0, remaining-sleepMargin)
+		m.sleep.fading = true
+	} else if remaining > m.sleep.fade && m.sleep.fading {
+		// Seeking back on the last track moves the end away again.
+		player.FadeTo(1, fadeRestore)
+		m.sleep.fading = false
+	}
+	return nil
+}
+
+func (m *Model) fireSleep() tea.Cmd {
+	quit := m.sleep.quit
+	m.sleep = sleepTimer{}
+
+	if quit {
+		notifier.Sleep("Quitting dicesong")
+		return tea.Quit
+	}
+	if m.playingIndex != -1 && !player.IsPaused() {
+		player.TogglePause()
+	}
+	player.FadeTo(1, fadeRestore)
+	notifier.Sleep("Paused playback")
+	return nil
+}
+
+// sleepLabel is the countdown shown in the player bar.
+func (m Model) sleepLabel() string {
+	if !m.sleep.active() {
+		return ""
+	}
+	if remaining, ok := m.sleepRemaining(); ok {
+		return "\U000f04b2 " + formatLength(max(remaining, time.Second))
+	}
+	if m.sleep.tracks == 1 {
+		return "\U000f04b2 1 track"
+	}
+	return fmt.Sprintf("\U000f04b2 %d tracks", m.sleep.tracks)
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSleepTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"45", 45 * time.Minute},
+		{"0", 0},
+		{"30m", 30 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"90s", 90 * time.Second},
+		{"1:30:00", 90 * time.Minute},
+		{"5:00", 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseSleepTime(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseSleepTime(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "soon", "1:2:3:4", "30 m"} {
+		if got, err := parseSleepTime(in); err == nil {
+			t.Errorf("parseSleepTime(%q) = %v, want an error", in, got)
+		}
+	}
+}
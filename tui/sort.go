@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"cmp"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/playlist"
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+var sortModes = []string{"name", "modified", "size", "duration", "track", "added"}
+
+const defaultSortMode = "name"
+
+func (m Model) sortOrder() state.SortOrder {
+	order := m.sorts[m.currentPath]
+	if !slices.Contains(sortModes, order.Mode) {
+		order.Mode = defaultSortMode
+	}
+	return order
+}
+
+func (m *Model) cycleSort() {
+	order := m.sortOrder()
+	order.Mode = sortModes[(slices.Index(sortModes, order.Mode)+1)%len(sortModes)]
+	m.setSortOrder(order)
+}
+
+func (m *Model) reverseSort() {
+	order := m.sortOrder()
+	order.Desc = !order.Desc
+	m.setSortOrder(order)
+}
+
+func (m *Model) setSortOrder(order state.SortOrder) {
+	if m.view != filesView || playlist.IsPlaylist(m.currentPath) {
+		return
+	}
+	if order == (state.SortOrder{Mode: defaultSortMode}) {
+		delete(m.sorts, m.currentPath)
+	} else {
+		m.sorts[m.currentPath] = order
+	}
+
+	m.refreshEntries()
+	saveState(*m)
+}
+
+func (m Model) sortLabel() string {
+	if m.view != filesView || playlist.IsPlaylist(m.currentPath) {
+		return ""
+	}
+	order := m.sortOrder()
+	arrow := "↑"
+	if order.Desc {
+		arrow = "↓"
+	}
+	return order.Mode + " " + arrow + "  "
+}
+
+// sortEntries orders a directory listing: folders first, then playlists,
+// then songs, each group by the chosen key with the natural name order
+// breaking ties.
+func (m Model) sortEntries(entries []fsEntry, order state.SortOrder) {
+	keys := make(map[string]int64, len(entries))
+	if order.Mode != defaultSortMode {
+		for _, entry := range entries {
+			keys[entry.path] = m.sortKey(entry, order.Mode)
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b fsEntry) int {
+		if a.isDir != b.isDir {
+			if a.isDir {
+				return -1
+			}
+			return 1
+		}
+		if a.isPlaylist != b.isPlaylist {
+			if a.isPlaylist {
+				return -1
+			}
+			return 1
+		}
+		c := cmp.Or(cmp.Compare(keys[a.path], keys[b.path]), naturalCompare(a.name, b.name))
+		if order.Desc {
+			return -c
+		}
+		return c
+	})
+}
+
+// sortingByDuration reports whether the browser lists a folder sorted
+// by duration, which needs the stream info of all of its songs.
+func (m Model) sortingByDuration() bool {
+	return !m.searchMode && m.view == filesView && !playlist.IsPlaylist(m.currentPath) && m.sortOrder().Mode == "duration"
+}
+
+func (m Model) sortKey(entry fsEntry, mode string) int64 {
+	switch mode {
+	case "modified", "size":
+		info, err := os.Stat(entry.path)
+		if err != nil {
+			return 0
+		}
+		if mode == "size" {
+			if entry.isDir {
+				return 0
+			}
+			return info.Size()
+		}
+		return info.ModTime().UnixNano()
+	}
+	if entry.browsable() {
+		return 0
+	}
+
+	switch mode {
+	case "duration":
+		// Songs not probed yet sort first until probeVisible reads them
+		// and the list is sorted again.
+		info, _ := m.library.Info(entry.path)
+		return int64(info.Duration)
+	case "track":
+		t := m.library.Track(entry.path).Tags
+		return int64(t.Disc)<<32 | int64(t.Track)
+	case "added":
+		return m.library.Track(entry.path).Added.UnixNano()
+	}
+	return 0
+}
+
+// naturalCompare compares names case-insensitively with runs of digits
+// compared by value, so "2 - y" sorts before "10 - x".
+func naturalCompare(a, b string) int {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if isDigit(ra[i]) && isDigit(rb[j]) {
+			si, sj := i, j
+			for i < len(ra) && isDigit(ra[i]) {
+				i++
+			}
+			for j < len(rb) && isDigit(rb[j]) {
+				j++
+			}
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if c := cmp.Or(cmp.Compare(len(na), len(nb)), cmp.Compare(na, nb)); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := cmp.Compare(ra[i], rb[j]); c != 0 {
+			return c
+		}
+		i++
+		j++
+	}
+	return cmp.Or(cmp.Compare(len(ra)-i, len(rb)-j), cmp.Compare(a, b))
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,32 @@
+package tui
+
+import "testing"
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"song", "song", 0},
+		{"2 - y", "10 - x", -1},
+		{"x10y2", "x10y10", -1},
+		{"big 99999999999999999999", "big 100000000000000000000", -1},
+		{"a", "B", -1},
+		{"a", "ab", -1},
+		{"a1", "a", 1},
+		{"track 1", "track a", -1},
+		// Equal names fall back to the raw order so sorting is stable.
+		{"track 01", "track 1", -1},
+		{"007", "7", -1},
+		{"ABC", "abc", -1},
+	}
+	for _, tt := range tests {
+		if got := naturalCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := naturalCompare(tt.b, tt.a); got != -tt.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
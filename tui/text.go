@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+type ellipsis int
+
+const (
+	ellipsisEnd ellipsis = iota
+	ellipsisMiddle
+	// ellipsisStart keeps the end of the text visible, for inputs being typed.
+	ellipsisStart
+)
+
+const ellipsisMark = "…"
+
+type cluster struct {
+	text  string
+	rune  int // index of the first rune in the original text
+	runes int
+	width int
+}
+
+func clusters(s string) ([]cluster, int) {
+	var out []cluster
+	total, pos := 0, 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		c := cluster{text: g.Str(), rune: pos, runes: len(g.Runes()), width: g.Width()}
+		out = append(out, c)
+		total += c.width
+		pos += c.runes
+	}
+	return out, total
+}
+
+func (m Model) nameEllipsis() ellipsis {
+	if m.MiddleEllipsis {
+		return ellipsisMiddle
+	}
+	return ellipsisEnd
+}
+
+// fitWidth shortens s to at most width terminal cells. It cuts between
+// grapheme clusters, so accents, emoji and wide CJK characters are never
+// split, and marks the cut with an ellipsis.
+func fitWidth(s string, width int, mode ellipsis) string {
+	out, _ := fitMatches(s, width, mode, nil)
+	return out
+}
+
+// fitMatches is fitWidth for highlighted text: matches are rune indices
+// into s and are moved to where those runes end up in the shortened text.
+func fitMatches(s string, width int, mode ellipsis, matches []int) (string, []int) {
+	parts, total := clusters(s)
+	if total <= width {
+		return s, matches
+	}
+	if width <= 0 {
+		return "", nil
+	}
+
+	budget := width - uniseg.StringWidth(ellipsisMark)
+	var head, tail int // number of cells kept before and after the mark
+	switch mode {
+	case ellipsisMiddle:
+		head = (budget + 1) / 2
+		tail = budget - head
+	case ellipsisStart:
+		tail = budget
+	default:
+		head = budget
+	}
+
+	i, used := 0, 0
+	for i < len(parts) && used+parts[i].width <= head {
+		used += parts[i].width
+		i++
+	}
+	j := len(parts)
+	used = 0
+	for j > i && used+parts[j-1].width <= tail {
+		j--
+		used += parts[j].width
+	}
+
+	positions := map[int]int{}
+	var out strings.Builder
+	next := 0
+	keep := func(c cluster) {
+		for r := range c.runes {
+			positions[c.rune+r] = next + r
+		}
+		next += c.runes
+		out.WriteString(c.text)
+	}
+	for _, c := range parts[:i] {
+		keep(c)
+	}
+	out.WriteString(ellipsisMark)
+	next += len([]rune(ellipsisMark))
+	for _, c := range parts[j:] {
+		keep(c)
+	}
+
+	var moved []int
+	for _, pos := range matches {
+		if p, ok := positions[pos]; ok {
+			moved = append(moved, p)
+		}
+	}
+	return out.String(), moved
+}
+
+// padWidth pads s with spaces to width terminal cells.
+func padWidth(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-uniseg.StringWidth(s), 0))
+}
+
+// wrapWidth splits s into lines of at most width terminal cells, breaking
+// between grapheme clusters so paths without spaces wrap too.
+func wrapWidth(s string, width int) []string {
+	width = max(width, 1)
+	parts, _ := clusters(s)
+	var lines []string
+	var line strings.Builder
+	used := 0
+	for _, c := range parts {
+		if used+c.width > width && used > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+			used = 0
+		}
+		line.WriteString(c.text)
+		used += c.width
+	}
+	return append(lines, line.String())
+}
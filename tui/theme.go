@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/theme"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var (
+	colorBg0     lipgloss.TerminalColor
+	colorBg1     lipgloss.TerminalColor
+	colorRed     lipgloss.TerminalColor
+	colorGreen   lipgloss.TerminalColor
+	colorYellow  lipgloss.TerminalColor
+	colorBlue    lipgloss.TerminalColor
+	colorMagenta lipgloss.TerminalColor
+	colorCyan    lipgloss.TerminalColor
+	colorFg      lipgloss.TerminalColor
+	colorGray    lipgloss.TerminalColor
+)
+
+var (
+	HeaderBoxStyle   lipgloss.Style
+	HeaderTitleStyle lipgloss.Style
+	HeaderInfoStyle  lipgloss.Style
+)
+
+var (
+	BrowserBoxStyle                 lipgloss.Style
+	BrowserPathStyle                lipgloss.Style
+	BrowserPathDimStyle             lipgloss.Style
+	BrowserSeparatorStyle           lipgloss.Style
+	BrowserItemStyle                lipgloss.Style
+	BrowserItemDirStyle             lipgloss.Style
+	BrowserItemSelectedStyle        lipgloss.Style
+	BrowserItemDirSelectedStyle     lipgloss.Style
+	BrowserItemPlayingStyle         lipgloss.Style
+	BrowserItemPlayingSelectedStyle lipgloss.Style
+	BrowserColumnStyle              lipgloss.Style
+	HelpKeyStyle                    lipgloss.Style
+)
+
+var (
+	PlayerBorderStyle            lipgloss.Style
+	NowPlayingIconStyle          lipgloss.Style
+	NowPlayingLabelStyle         lipgloss.Style
+	NowPlayingSongStyle          lipgloss.Style
+	NowPlayingTextStyle          lipgloss.Style
+	NowPlayingIdleIconStyle      lipgloss.Style
+	NowPlayingIdleTextStyle      lipgloss.Style
+	NowPlayingErrorIconStyle     lipgloss.Style
+	NowPlayingErrorTextStyle     lipgloss.Style
+	PlayerTimeStyle              lipgloss.Style
+	PlayerProgressFilledStyle    lipgloss.Style
+	PlayerProgressIndicatorStyle lipgloss.Style
+	PlayerProgressEmptyStyle     lipgloss.Style
+	ControlButtonStyle           lipgloss.Style
+	ControlButtonActiveStyle     lipgloss.Style
+	ControlButtonOnStyle         lipgloss.Style
+	ControlButtonOffStyle        lipgloss.Style
+)
+
+func init() {
+	applyTheme(theme.Builtin()[0])
+}
+
+// colorless reports whether the terminal shows no colors at all, either
+// because of NO_COLOR or because it cannot.
+func colorless() bool {
+	return lipgloss.ColorProfile() == termenv.Ascii
+}
+
+// currentTheme is the theme in use, which is always monochrome when the
+// terminal has no colors.
+func (m Model) currentTheme() theme.Theme {
+	if colorless() {
+		return theme.Find(m.Themes, theme.Mono)
+	}
+	return theme.Find(m.Themes, m.themeName)
+}
+
+func (m *Model) nextTheme() {
+	if colorless() {
+		return
+	}
+	m.themeName = theme.Next(m.Themes, m.currentTheme().Name).Name
+	applyTheme(m.currentTheme())
+	saveState(*m)
+}
+
+func (m *Model) setTheme(name string) error {
+	if colorless() {
+		return errors.New("the terminal has no colors")
+	}
+	i := slices.IndexFunc(m.Themes, func(t theme.Theme) bool { return strings.EqualFold(t.Name, name) })
+	if i < 0 {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+	m.themeName = m.Themes[i].Name
+	applyTheme(m.currentTheme())
+	saveState(*m)
+	return nil
+}
+
+func applyTheme(t theme.Theme) {
+	color := func(hex string) lipgloss.TerminalColor {
+		if t.NoColor {
+			return lipgloss.NoColor{}
+		}
+		return lipgloss.Color(hex)
+	}
+	colorBg0 = color(t.Bg0)
+	colorBg1 = color(t.Bg1)
+	colorRed = color(t.Red)
+	colorGreen = color(t.Green)
+	colorYellow = color(t.Yellow)
+	colorBlue = color(t.Blue)
+	colorMagenta = color(t.Magenta)
+	colorCyan = color(t.Cyan)
+	colorFg = color(t.Fg)
+	colorGray = color(t.Gray)
+
+	// Without colors the selected row has no background to stand out.
+	selected := lipgloss.NewStyle().Background(colorBg1).Bold(true).Reverse(t.NoColor)
+
+	HeaderBoxStyle = lipgloss.NewStyle().Background(colorBg1).BorderStyle(lipgloss.RoundedBorder()).BorderForeground(colorGray).BorderBottom(true).Padding(0, 1)
+	HeaderTitleStyle = lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+	HeaderInfoStyle = lipgloss.NewStyle().Italic(true)
+
+	BrowserBoxStyle = lipgloss.NewStyle().Background(colorBg0).Padding(0)
+	BrowserPathStyle = lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Background(colorBg1)
+	BrowserPathDimStyle = lipgloss.NewStyle().Foreground(colorGray).Background(colorBg1).Faint(t.NoColor)
+	BrowserSeparatorStyle = lipgloss.NewStyle().Foreground(colorGray)
+	BrowserItemStyle = lipgloss.NewStyle().Foreground(colorFg)
+	BrowserItemDirStyle = lipgloss.NewStyle().Foreground(colorCyan).Bold(true)
+	BrowserItemSelectedStyle = selected.Foreground(colorYellow)
+	BrowserItemDirSelectedStyle = selected.Foreground(colorCyan)
+	BrowserItemPlayingStyle = lipgloss.NewStyle().Foreground(colorGreen).Bold(true)
+	BrowserItemPlayingSelectedStyle = selected.Foreground(colorGreen)
+	BrowserColumnStyle = lipgloss.NewStyle().Foreground(colorGray).Faint(t.NoColor)
+	HelpKeyStyle = lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+
+	PlayerBorderStyle = lipgloss.NewStyle().Foreground(colorGray)
+	NowPlayingIconStyle = lipgloss.NewStyle().Foreground(colorMagenta).Bold(true)
+	NowPlayingLabelStyle = lipgloss.NewStyle().Foreground(colorFg)
+	NowPlayingSongStyle = lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+	NowPlayingTextStyle = lipgloss.NewStyle().Foreground(colorFg)
+	NowPlayingIdleIconStyle = lipgloss.NewStyle().Foreground(colorGray)
+	NowPlayingIdleTextStyle = lipgloss.NewStyle().Foreground(colorGray).Italic(true)
+	NowPlayingErrorIconStyle = lipgloss.NewStyle().Foreground(colorRed).Bold(true)
+	NowPlayingErrorTextStyle = lipgloss.NewStyle().Foreground(colorRed)
+	PlayerTimeStyle = lipgloss.NewStyle().Foreground(colorGray)
+	PlayerProgressFilledStyle = lipgloss.NewStyle().Foreground(colorGreen).Bold(true)
+	PlayerProgressIndicatorStyle = lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
+	PlayerProgressEmptyStyle = lipgloss.NewStyle().Foreground(colorGray).Faint(t.NoColor)
+	ControlButtonStyle = lipgloss.NewStyle().Foreground(colorFg)
+	ControlButtonActiveStyle = lipgloss.NewStyle().Foreground(colorBlue).Bold(true)
+	ControlButtonOnStyle = lipgloss.NewStyle().Foreground(colorGreen).Bold(true)
+	ControlButtonOffStyle = lipgloss.NewStyle().Foreground(colorGray).Faint(t.NoColor)
+}
@@ -0,0 +1,287 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/library"
+	"github.com/Gylmynnn/dicesong/playlist"
+)
+
+type libraryView int
+
+const (
+	filesView libraryView = iota
+	artistsView
+	genresView
+	yearsView
+)
+
+var viewNames = []string{"Files", "Artists", "Genres", "Years"}
+
+const (
+	unknownArtist = "Unknown Artist"
+	unknownAlbum  = "Unknown Album"
+	unknownGenre  = "Unknown Genre"
+	unknownYear   = "Unknown Year"
+
+	// albumSep joins artist and album into one group key so albums with
+	// the same title by different artists stay apart.
+	albumSep = "\x1f"
+
+	// groupLevels is the number of group levels above the track list in
+	// every tag view: artist/genre/year, then album.
+	groupLevels = 2
+)
+
+func (m *Model) cycleView() {
+	m.view = (m.view + 1) % libraryView(len(viewNames))
+	m.viewPath = nil
+	m.entries = m.readEntries()
+	m.cursor = 0
+	m.offset = 0
+}
+
+// readEntries lists the current directory, playlist or library group.
+func (m Model) readEntries() []fsEntry {
+	if m.view == filesView {
+		entries, _ := readDir(m.currentPath)
+		if !playlist.IsPlaylist(m.currentPath) {
+			m.sortEntries(entries, m.sortOrder())
+		}
+		return entries
+	}
+	return m.groupEntries(m.viewTracks(m.viewPath), len(m.viewPath))
+}
+
+// openEntry descends into a directory, playlist or library group.
+func (m *Model) openEntry(entry fsEntry) {
+	if m.view == filesView {
+		m.currentPath = entry.path
+	} else {
+		m.viewPath = append(slices.Clone(m.viewPath), entry.path)
+	}
+	m.entries = m.readEntries()
+	m.cursor = 0
+	m.offset = 0
+}
+
+// refreshEntries rereads the list, keeping the cursor on the selected
+// entry, or within the list when the entry is gone.
+func (m *Model) refreshEntries() {
+	var selected string
+	if m.cursor < len(m.entries) {
+		selected = m.entries[m.cursor].path
+	}
+	m.entries = m.readEntries()
+	if i := slices.IndexFunc(m.entries, func(e fsEntry) bool { return e.path == selected }); i >= 0 {
+		m.cursor = i
+	}
+	moveCursor(&m.cursor, &m.offset, 0, len(m.entries), m.height-16)
+}
+
+func (m *Model) goBack() {
+	if m.view != filesView {
+		if len(m.viewPath) == 0 {
+			return
+		}
+		last := m.viewPath[len(m.viewPath)-1]
+		m.viewPath = m.viewPath[:len(m.viewPath)-1]
+		m.entries = m.readEntries()
+		m.cursor = max(slices.IndexFunc(m.entries, func(e fsEntry) bool { return e.path == last }), 0)
+		m.offset = max(m.cursor-(m.height-16)+1, 0)
+		return
+	}
+
+	parentDir := filepath.Dir(m.currentPath)
+	if parentDir != "." && parentDir != "" && strings.HasPrefix(m.currentPath, m.musicRoot) && m.currentPath != m.musicRoot {
+		m.currentPath = parentDir
+		m.entries = m.readEntries()
+		m.cursor = 0
+		m.offset = 0
+	}
+}
+
+// contextQueue is the queue a song picked from the browser plays within:
+// the open album or playlist, or the whole library.
+func (m Model) contextQueue() []string {
+	switch {
+	case m.view != filesView:
+		var songs []string
+		for _, entry := range m.readEntries() {
+			if !entry.browsable() {
+				songs = append(songs, entry.path)
+			}
+		}
+		return songs
+	case playlist.IsPlaylist(m.currentPath):
+		if tracks, err := playlist.Load(m.currentPath); err == nil {
+			return playlist.Paths(tracks)
+		}
+	}
+	return m.allSongs
+}
+
+// groupSongs returns the songs under a group entry of the current level,
+// in play order.
+func (m Model) groupSongs(key string) []string {
+	tracks := m.viewTracks(append(slices.Clone(m.viewPath), key))
+	sortTracks(tracks)
+	songs := make([]string, len(tracks))
+	for i, track := range tracks {
+		songs[i] = track.Path
+	}
+	return songs
+}
+
+func (m Model) viewTracks(path []string) []library.Track {
+	tracks := m.library.Tracks()
+	for level, key := range path {
+		tracks = slices.DeleteFunc(tracks, func(t library.Track) bool {
+			return m.groupKey(t, level) != key
+		})
+	}
+	return tracks
+}
+
+func (m Model) groupKey(t library.Track, level int) string {
+	if level > 0 {
+		return trackArtist(t) + albumSep + cmp.Or(t.Tags.Album, unknownAlbum)
+	}
+	switch m.view {
+	case genresView:
+		return cmp.Or(t.Tags.Genre, unknownGenre)
+	case yearsView:
+		if t.Tags.Year == 0 {
+			return unknownYear
+		}
+		return strconv.Itoa(t.Tags.Year)
+	default:
+		return trackArtist(t)
+	}
+}
+
+func (m Model) groupEntries(tracks []library.Track, level int) []fsEntry {
+	if level >= groupLevels {
+		sortTracks(tracks)
+		entries := make([]fsEntry, len(tracks))
+		for i, t := range tracks {
+			name := cmp.Or(t.Tags.Title, filepath.Base(t.Path))
+			if t.Tags.Track > 0 {
+				name = fmt.Sprintf("%02d. %s", t.Tags.Track, name)
+			}
+			entries[i] = fsEntry{name: name, path: t.Path}
+		}
+		return entries
+	}
+
+	type group struct {
+		key   string
+		year  int
+		count int
+	}
+	var groups []*group
+	byKey := map[string]*group{}
+	for _, t := range tracks {
+		key := m.groupKey(t, level)
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{key: key}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.count++
+		if t.Tags.Year > 0 && (g.year == 0 || t.Tags.Year < g.year) {
+			g.year = t.Tags.Year
+		}
+	}
+
+	slices.SortFunc(groups, func(a, b *group) int {
+		if unknown(a.key) != unknown(b.key) {
+			if unknown(a.key) {
+				return 1
+			}
+			return -1
+		}
+		if level > 0 && a.year != b.year {
+			return cmp.Compare(a.year, b.year)
+		}
+		return cmp.Compare(strings.ToLower(a.key), strings.ToLower(b.key))
+	})
+
+	entries := make([]fsEntry, len(groups))
+	for i, g := range groups {
+		name := g.key
+		if level > 0 {
+			name = m.albumLabel(g.key)
+			if g.year > 0 {
+				name += fmt.Sprintf(" (%d)", g.year)
+			}
+		}
+		entries[i] = fsEntry{
+			name:  fmt.Sprintf("%s  [%d]", name, g.count),
+			path:  g.key,
+			isDir: true,
+		}
+	}
+	return entries
+}
+
+func (m Model) albumLabel(key string) string {
+	artist, album, _ := strings.Cut(key, albumSep)
+	if m.view == artistsView {
+		return album
+	}
+	return album + " — " + artist
+}
+
+// viewTitle is the breadcrumb shown in place of the directory path.
+func (m Model) viewTitle() string {
+	parts := []string{viewNames[m.view]}
+	for level, key := range m.viewPath {
+		if level > 0 {
+			key = m.albumLabel(key)
+		}
+		parts = append(parts, key)
+	}
+	return strings.Join(parts, " / ")
+}
+
+func (m Model) displayPath() string {
+	if m.view != filesView {
+		return m.viewTitle()
+	}
+	home, _ := os.UserHomeDir()
+	if after, ok := strings.CutPrefix(m.currentPath, home); ok {
+		return "~" + after
+	}
+	return m.currentPath
+}
+
+func trackArtist(t library.Track) string {
+	return cmp.Or(t.Tags.AlbumArtist, t.Tags.Artist, unknownArtist)
+}
+
+func unknown(key string) bool {
+	switch key {
+	case unknownArtist, unknownGenre, unknownYear:
+		return true
+	}
+	return strings.HasSuffix(key, albumSep+unknownAlbum)
+}
+
+// sortTracks orders tracks the way an album is meant to be played.
+func sortTracks(tracks []library.Track) {
+	slices.SortStableFunc(tracks, func(a, b library.Track) int {
+		return cmp.Or(
+			cmp.Compare(a.Tags.Disc, b.Tags.Disc),
+			cmp.Compare(a.Tags.Track, b.Tags.Track),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+}
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Gylmynnn/dicesong/keymap"
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/Gylmynnn/dicesong/spectrum"
+)
+
+const (
+	visualSamples = 2048
+	scopeSamples  = 1024
+	// bandFall is how much of its height a band keeps each tick when the
+	// music gets quieter, so bars sink instead of flickering.
+	bandFall = 0.75
+)
+
+type visualStyle string
+
+const (
+	barsStyle   visualStyle = "bars"
+	blocksStyle visualStyle = "blocks"
+	scopeStyle  visualStyle = "scope"
+)
+
+var visualStyles = []visualStyle{barsStyle, blocksStyle, scopeStyle}
+
+var eighths = []rune(" ▁▂▃▄▅▆▇█")
+
+// visualizer holds what the visualizer pane draws, worked out on each
+// tick from the samples the player last sent to the speaker.
+type visualizer struct {
+	style visualStyle
+	bands []float64
+	wave  []float64
+}
+
+func newVisualizer(style string) visualizer {
+	v := visualizer{style: visualStyle(style)}
+	if !slices.Contains(visualStyles, v.style) {
+		v.style = barsStyle
+	}
+	return v
+}
+
+// visualizerShown reports whether the visualizer pane is on screen. Only
+// then does the player record samples for it.
+func (m Model) visualizerShown() bool {
+	i := m.layout.index(visualizerPane)
+	return i >= 0 && (m.columnWidths() != nil || m.layout.focus == i+1)
+}
+
+func (m *Model) updateVisualizer() {
+	shown := m.visualizerShown()
+	player.SetTap(shown)
+	if !shown {
+		m.visual.bands, m.visual.wave = nil, nil
+		return
+	}
+
+	samples := make([]float64, visualSamples)
+	if m.playingIndex != -1 && !m.loading && !player.IsPaused() {
+		player.Samples(samples)
+	}
+	width := max(m.paneWidth(visualizerPane)-4, 1)
+
+	switch m.visual.style {
+	case scopeStyle:
+		m.visual.wave = spectrum.Scope(samples[visualSamples-scopeSamples:], width*2)
+	default:
+		count := width
+		if m.visual.style == blocksStyle {
+			count = width / 2
+		}
+		format, _ := player.CurrentFormat()
+		bands := spectrum.Bands(samples, int(format.SampleRate), count)
+		if len(m.visual.bands) == count {
+			for i := range bands {
+				bands[i] = max(bands[i], m.visual.bands[i]*bandFall)
+			}
+		}
+		m.visual.bands = bands
+	}
+}
+
+func (m *Model) cycleVisualStyle() {
+	i := slices.Index(visualStyles, m.visual.style)
+	m.visual = visualizer{style: visualStyles[(i+1)%len(visualStyles)]}
+	saveState(*m)
+}
+
+func (m *Model) visualizerAction(action keymap.Action) bool {
+	switch action {
+	case keymap.Select, keymap.Open:
+		m.cycleVisualStyle()
+	case keymap.Back:
+	default:
+		return false
+	}
+	return true
+}
+
+func (m Model) renderVisualizer(height int) string {
+	var content strings.Builder
+
+	title := fmt.Sprintf("\uf028 Visualizer (%s)", m.visual.style)
+	content.WriteString(m.titleStyle().Render("  "+fitWidth(title, m.width-4, ellipsisEnd)+"  ") + "\n")
+	content.WriteString(BrowserSeparatorStyle.Render(strings.Repeat("─", m.width)) + "\n")
+
+	rows := max(height-3, 1)
+	var lines []string
+	switch m.visual.style {
+	case scopeStyle:
+		lines = renderScope(m.visual.wave, rows)
+	case blocksStyle:
+		lines = renderBars(m.visual.bands, rows, false)
+	default:
+		lines = renderBars(m.visual.bands, rows, true)
+	}
+	for i, line := range lines {
+		style := PlayerProgressFilledStyle
+		if m.visual.style != scopeStyle && i < rows/4 {
+			style = PlayerProgressIndicatorStyle
+		}
+		content.WriteString("  " + style.Render(line) + "\n")
+	}
+
+	return BrowserBoxStyle.
+		Width(m.width).
+		Height(height).
+		Render(content.String())
+}
+
+// renderBars draws one bar per band from the bottom up, in eighths of a
+// row when smooth and otherwise as whole cells two columns apart.
+func renderBars(bands []float64, rows int, smooth bool) []string {
+	lines := make([]string, rows)
+	for r := range lines {
+		level := rows - 1 - r
+		var line strings.Builder
+		for _, band := range bands {
+			if !smooth {
+				if level < int(band*float64(rows)+0.5) {
+					line.WriteString("█ ")
+				} else {
+					line.WriteString("  ")
+				}
+				continue
+			}
+			fill := int(band * float64(rows*8))
+			line.WriteRune(eighths[min(max(fill-level*8, 0), 8)])
+		}
+		lines[r] = line.String()
+	}
+	return lines
+}
+
+// renderScope plots the waveform in braille, two points per column and
+// four per row, joining neighbouring points with vertical strokes.
+func renderScope(wave []float64, rows int) []string {
+	columns := len(wave) / 2
+	cells := make([][]rune, rows)
+	for r := range cells {
+		cells[r] = []rune(strings.Repeat("\u2800", columns))
+	}
+	dots := [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+	height := rows * 4
+	y := func(v float64) int {
+		return min(max(int((1-v)/2*float64(height-1)+0.5), 0), height-1)
+	}
+	for x, v := range wave {
+		from, to := y(v), y(v)
+		if x > 0 {
+			previous := y(wave[x-1])
+			from, to = min(from, previous), max(to, previous)
+		}
+		for dot := from; dot <= to; dot++ {
+			cells[dot/4][x/2] |= dots[x%2][dot%4]
+		}
+	}
+
+	lines := make([]string, rows)
+	for r, row := range cells {
+		lines[r] = string(row)
+	}
+	return lines
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"github.com/Gylmynnn/dicesong/waveform"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type waveformLoadedMsg struct{ waveform songWaveform }
+
+// songWaveform is the peak envelope of the playing song.
+type songWaveform struct {
+	path  string
+	peaks []float64
+	err   error
+}
+
+func readWaveform(path string) tea.Cmd {
+	return func() tea.Msg {
+		peaks, err := waveform.Load(path)
+		return waveformLoadedMsg{songWaveform{path: path, peaks: peaks, err: err}}
+	}
+}
+
+// loadWaveform works out the envelope of the playing song whenever it
+// changes while the waveform bar is on.
+func (m *Model) loadWaveform() tea.Cmd {
+	if !m.showWaveform || m.loadingPeaks {
+		return nil
+	}
+	song := m.playingSong()
+	if song == "" || song == m.waveform.path {
+		return nil
+	}
+	m.loadingPeaks = true
+	return readWaveform(song)
+}
+
+func (m *Model) handleWaveformLoaded(msg waveformLoadedMsg) tea.Cmd {
+	m.loadingPeaks = false
+	m.waveform = msg.waveform
+	return m.loadWaveform()
+}
+
+func (m *Model) toggleWaveform() {
+	m.showWaveform = !m.showWaveform
+	saveState(*m)
+}
+
+// playingPeaks returns the envelope of the playing song once it is known.
+func (m Model) playingPeaks() []float64 {
+	if !m.showWaveform || m.waveform.path != m.playingSong() || m.waveform.err != nil {
+		return nil
+	}
+	return m.waveform.peaks
+}
+
+// renderWaveform draws the envelope in block characters scaled to the
+// loudest peak, colored by whether that part has been played.
+func renderWaveform(peaks []float64, current, total float64, width int) string {
+	loudest := 0.0
+	for _, peak := range peaks {
+		loudest = max(loudest, peak)
+	}
+	if loudest == 0 || total <= 0 {
+		return renderProgressBar(current, total, width)
+	}
+
+	played := min(max(int(current/total*float64(width)), 0), width-1)
+	columns := make([]rune, width)
+	for x := range columns {
+		from := x * len(peaks) / width
+		to := max((x+1)*len(peaks)/width, from+1)
+		peak := 0.0
+		for _, p := range peaks[from:min(to, len(peaks))] {
+			peak = max(peak, p)
+		}
+		columns[x] = eighths[min(max(int(peak/loudest*8+0.5), 1), 8)]
+	}
+
+	return renderRun(PlayerProgressFilledStyle, columns[:played]) +
+		renderRun(PlayerProgressIndicatorStyle, columns[played:played+1]) +
+		renderRun(PlayerProgressEmptyStyle, columns[played+1:])
+}
+
+func renderRun(style lipgloss.Style, runes []rune) string {
+	if len(runes) == 0 {
+		return ""
+	}
+	return style.Render(string(runes))
+}
@@ -0,0 +1,98 @@
+package waveform
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/Gylmynnn/dicesong/player"
+	"github.com/Gylmynnn/dicesong/state"
+)
+
+const (
+	cacheDir  = "waveforms"
+	cacheExt  = ".peaks"
+	chunkSize = 8192
+	// Resolution is how many peaks a song is split into.
+	Resolution = 1024
+)
+
+var ErrUnknownLength = errors.New("song length unknown")
+
+// Load returns the peak envelope of the song at path, from 0 to 1 over
+// Resolution equal slices of it. Envelopes are cached in the data
+// directory by path, size and modification time, one byte per peak.
+func Load(path string) ([]float64, error) {
+	cache, err := cachePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(cache)
+	if err != nil || len(data) != Resolution {
+		if data, err = compute(path); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(cache), 0o755); err == nil {
+			_ = os.WriteFile(cache, data, 0o644)
+		}
+	}
+
+	peaks := make([]float64, len(data))
+	for i, b := range data {
+		peaks[i] = float64(b) / math.MaxUint8
+	}
+	return peaks, nil
+}
+
+func cachePath(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano())
+	return filepath.Join(state.DataDir(), cacheDir, hex.EncodeToString(h.Sum(nil))+cacheExt), nil
+}
+
+// compute decodes the whole song and keeps the loudest sample of each
+// slice, rounded up so that quiet parts still show.
+func compute(path string) ([]byte, error) {
+	stream, _, err := player.Decode(path)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	length := stream.Len()
+	if length <= 0 {
+		return nil, ErrUnknownLength
+	}
+
+	peaks := make([]float64, Resolution)
+	buf := make([][2]float64, chunkSize)
+	position := 0
+	for {
+		n, ok := stream.Stream(buf)
+		for _, s := range buf[:n] {
+			i := min(position*Resolution/length, Resolution-1)
+			peaks[i] = max(peaks[i], math.Abs(s[0]), math.Abs(s[1]))
+			position++
+		}
+		if !ok {
+			break
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, Resolution)
+	for i, peak := range peaks {
+		data[i] = byte(math.Ceil(min(peak, 1) * math.MaxUint8))
+	}
+	return data, nil
+}